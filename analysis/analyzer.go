@@ -0,0 +1,134 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"github.com/procyon-projects/marker"
+	"github.com/procyon-projects/marker/packages"
+	"go/ast"
+	"go/token"
+	goanalysis "golang.org/x/tools/go/analysis"
+	gopackages "golang.org/x/tools/go/packages"
+)
+
+// AnalyzerName is the name of the analyzer.
+const AnalyzerName = "markers"
+
+// NewAnalyzer returns an analyzer which collects the marker comments of the package by using
+// the definitions in the given registry, and reports the parse, validation and deprecation problems.
+func NewAnalyzer(registry *markers.Registry) *goanalysis.Analyzer {
+	return &goanalysis.Analyzer{
+		Name: AnalyzerName,
+		Doc:  "check the marker comments\n\nThe analyzer reports the marker comments which cannot be parsed or validated, and the deprecated markers in use.",
+		Run: func(pass *goanalysis.Pass) (any, error) {
+			return nil, run(pass, registry)
+		},
+	}
+}
+
+func run(pass *goanalysis.Pass, registry *markers.Registry) error {
+	if len(pass.Files) == 0 {
+		return nil
+	}
+
+	pkg := &packages.Package{
+		Package: &gopackages.Package{
+			ID:        pass.Pkg.Path(),
+			Name:      pass.Pkg.Name(),
+			PkgPath:   pass.Pkg.Path(),
+			Fset:      pass.Fset,
+			Syntax:    pass.Files,
+			Types:     pass.Pkg,
+			TypesInfo: pass.TypesInfo,
+		},
+	}
+
+	collector := markers.NewCollector(registry)
+	collector.WarningCallback = func(warning markers.Warning) {
+		pass.Report(goanalysis.Diagnostic{
+			Pos:     findPos(pass, warning.FileName, warning.Position),
+			Message: warning.Message,
+		})
+	}
+
+	_, err := collector.Collect(pkg)
+
+	for _, err = range markers.FlattenErrors(err) {
+		pass.Report(newDiagnostic(pass, err))
+	}
+
+	return nil
+}
+
+// newDiagnostic returns the diagnostic for the given error. A suggested fix is added
+// if the error knows the text which can replace the invalid one.
+func newDiagnostic(pass *goanalysis.Pass, err error) goanalysis.Diagnostic {
+	var parserErr markers.ParserError
+
+	if !errors.As(err, &parserErr) {
+		return goanalysis.Diagnostic{
+			Pos:     pass.Files[0].Package,
+			Message: err.Error(),
+		}
+	}
+
+	diagnostic := goanalysis.Diagnostic{
+		Pos:     findPos(pass, parserErr.FileName, parserErr.Position),
+		Message: err.Error(),
+	}
+
+	var length int
+	var suggestion string
+	var scannerErr markers.ScannerError
+	var unknownArgumentErr markers.UnknownArgumentError
+
+	if errors.As(err, &scannerErr) {
+		suggestion, length = scannerErr.Suggestion, scannerErr.Length
+	} else if errors.As(err, &unknownArgumentErr) {
+		suggestion, length = unknownArgumentErr.Suggestion, len(unknownArgumentErr.Argument)
+	}
+
+	if suggestion == "" || !diagnostic.Pos.IsValid() {
+		return diagnostic
+	}
+
+	diagnostic.End = diagnostic.Pos + token.Pos(length)
+	diagnostic.SuggestedFixes = []goanalysis.SuggestedFix{
+		{
+			Message: fmt.Sprintf("Replace with '%s'", suggestion),
+			TextEdits: []goanalysis.TextEdit{
+				{
+					Pos:     diagnostic.Pos,
+					End:     diagnostic.End,
+					NewText: []byte(suggestion),
+				},
+			},
+		},
+	}
+
+	return diagnostic
+}
+
+// findPos returns the position in the file with the given name corresponding to the given line and column.
+func findPos(pass *goanalysis.Pass, fileName string, position markers.Position) token.Pos {
+	var file *ast.File
+
+	for _, candidate := range pass.Files {
+		if pass.Fset.File(candidate.Pos()).Name() == fileName {
+			file = candidate
+			break
+		}
+	}
+
+	if file == nil {
+		return pass.Files[0].Package
+	}
+
+	tokenFile := pass.Fset.File(file.Pos())
+
+	if position.Line < 1 || position.Line > tokenFile.LineCount() {
+		return file.Package
+	}
+
+	return tokenFile.LineStart(position.Line) + token.Pos(position.Column-1)
+}
@@ -0,0 +1,33 @@
+package analysis
+
+import (
+	"github.com/procyon-projects/marker"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"testing"
+)
+
+type cacheEntriesMarker struct {
+	Order string   `parameter:"Order" enum:"asc,desc" required:"true"`
+	Tags  []string `parameter:"Tags" enum:"users,orders"`
+}
+
+type cacheMemoMarker struct {
+}
+
+func newTestRegistry(t *testing.T) *markers.Registry {
+	registry := markers.NewRegistry()
+	assert.NoError(t, registry.Register("cache:entries", "github.com/example/cache", markers.FunctionLevel, &cacheEntriesMarker{}))
+
+	definition, err := markers.MakeDefinition("cache:memo", "github.com/example/cache", markers.FunctionLevel, &cacheMemoMarker{})
+	assert.NoError(t, err)
+	definition.Deprecated = true
+	definition.DeprecationMessage = "use cache:entries instead"
+	assert.NoError(t, registry.RegisterWithDefinition(definition))
+
+	return registry
+}
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), NewAnalyzer(newTestRegistry(t)), "cache")
+}
@@ -0,0 +1,25 @@
+// +import=cache, Pkg=github.com/example/cache
+package cache
+
+// +cache:entries:Order=asc, Tags={users}
+func FindUsers() {}
+
+// want +1 "the marker 'cache:entries' does not have an argument named 'Tgs', did you mean 'Tags'\\?"
+// +cache:entries:Order=asc, Tgs={users}
+func FindOrders() {}
+
+// want +1 `invalid value "des", allowed values are asc, desc; did you mean "desc"\?`
+// +cache:entries:Order=des, Tags={users}
+func FindAdmins() {}
+
+// want +1 `invalid value "oders", allowed values are orders, users; did you mean "orders"\?`
+// +cache:entries:Order=asc, Tags={users, oders}
+func FindCustomers() {}
+
+// want +1 `missing argument "Order"`
+// +cache:entries:Tags={users}
+func FindProducts() {}
+
+// want +1 "the marker 'cache:memo' is deprecated: use cache:entries instead"
+// +cache:memo
+func FindCategories() {}
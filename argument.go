@@ -2,19 +2,39 @@ package markers
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 )
 
 type Argument struct {
-	Name       string
-	TypeInfo   ArgumentTypeInfo
-	Required   bool
-	Deprecated bool
-	Default    any
+	Name               string
+	Description        string
+	TypeInfo           ArgumentTypeInfo
+	Required           bool
+	Deprecated         bool
+	DeprecationMessage string
+	Default            any
+}
+
+// sortedArgumentNames returns the names of the given arguments in alphabetical order.
+func sortedArgumentNames(arguments map[string]Argument) []string {
+	names := make([]string, 0, len(arguments))
+
+	for name := range arguments {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
 }
 
 func ExtractArgument(structField reflect.StructField) (Argument, error) {
+	return extractArgument(structField, make(map[reflect.Type]bool))
+}
+
+func extractArgument(structField reflect.StructField, visiting map[reflect.Type]bool) (Argument, error) {
 	parameterName := UpperCamelCase(structField.Name)
 	parameterTag, parameterTagExists := structField.Tag.Lookup("parameter")
 
@@ -23,7 +43,7 @@ func ExtractArgument(structField reflect.StructField) (Argument, error) {
 	}
 
 	fieldType := structField.Type
-	argumentTypeInfo, err := ArgumentTypeInfoFromType(fieldType)
+	argumentTypeInfo, err := argumentTypeInfoFromType(fieldType, visiting)
 
 	if err != nil {
 		return Argument{}, err
@@ -38,17 +58,11 @@ func ExtractArgument(structField reflect.StructField) (Argument, error) {
 	}
 
 	isDeprecated := false
-	_, deprecatedTagExists := structField.Tag.Lookup("deprecated")
+	deprecatedTag, deprecatedTagExists := structField.Tag.Lookup("deprecated")
 	if deprecatedTagExists {
 		isDeprecated = true
 	}
 
-	defaultValue := ""
-	defaultTag, defaultTagExists := structField.Tag.Lookup("default")
-	if defaultTagExists && defaultTag != "" {
-		defaultValue = defaultTag
-	}
-
 	enumTag, enumTagExists := structField.Tag.Lookup("enum")
 	if enumTagExists && enumTag != "" {
 		if argumentTypeInfo.ActualType != StringType && (argumentTypeInfo.ActualType != SliceType || argumentTypeInfo.ItemType.ActualType != StringType) {
@@ -64,13 +78,29 @@ func ExtractArgument(structField reflect.StructField) (Argument, error) {
 				argumentTypeInfo.Enum[enumKeyValueParts[0]] = enumKeyValueParts[0]
 			}
 		}
+
+		if argumentTypeInfo.ActualType == SliceType {
+			argumentTypeInfo.ItemType.Enum = argumentTypeInfo.Enum
+		}
+	}
+
+	var defaultValue any
+	defaultTag, defaultTagExists := structField.Tag.Lookup("default")
+	if defaultTagExists {
+		defaultValue, err = argumentTypeInfo.parseText(fieldType, defaultTag)
+
+		if err != nil {
+			return Argument{}, fmt.Errorf("default value of argument '%s' cannot be parsed: %w", parameterName, err)
+		}
 	}
 
 	return Argument{
-		Name:       parameterName,
-		TypeInfo:   argumentTypeInfo,
-		Required:   isRequired,
-		Deprecated: isDeprecated,
-		Default:    defaultValue,
+		Name:               parameterName,
+		Description:        structField.Tag.Get("description"),
+		TypeInfo:           argumentTypeInfo,
+		Required:           isRequired,
+		Deprecated:         isDeprecated,
+		DeprecationMessage: deprecatedTag,
+		Default:            defaultValue,
 	}, nil
 }
@@ -0,0 +1,97 @@
+package markers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+type defaultValueMarker struct {
+	Name    string         `parameter:"Name" default:"anyName"`
+	Size    int            `parameter:"Size" default:"42"`
+	Enabled bool           `parameter:"Enabled" default:"true"`
+	Items   []string       `parameter:"Items" default:"{a,b}"`
+	Labels  map[string]int `parameter:"Labels" default:"{a:1,b:2}"`
+	Limit   *uint          `parameter:"Limit" default:"8"`
+	Any     any            `parameter:"Any" default:"{1,2}"`
+	Other   string         `parameter:"Other"`
+}
+
+type invalidDefaultValueMarker struct {
+	Size int `parameter:"Size" default:"abc"`
+}
+
+func TestExtractArgument_DefaultValue(t *testing.T) {
+	limit := uint(8)
+	testCases := map[string]any{
+		"Name":    "anyName",
+		"Size":    42,
+		"Enabled": true,
+		"Items":   []string{"a", "b"},
+		"Labels":  map[string]int{"a": 1, "b": 2},
+		"Limit":   &limit,
+		"Any":     []any{1, 2},
+		"Other":   nil,
+	}
+
+	outputType := reflect.TypeOf(defaultValueMarker{})
+
+	for index := 0; index < outputType.NumField(); index++ {
+		field := outputType.Field(index)
+		argument, err := ExtractArgument(field)
+
+		assert.NoError(t, err)
+		assert.Equal(t, testCases[field.Name], argument.Default, "default value of %s", field.Name)
+	}
+}
+
+func TestExtractArgument_InvalidDefaultValue(t *testing.T) {
+	field, _ := reflect.TypeOf(invalidDefaultValueMarker{}).FieldByName("Size")
+	_, err := ExtractArgument(field)
+
+	assert.Error(t, err)
+	assert.Equal(t, "default value of argument 'Size' cannot be parsed: expected integer, got \"abc\"", err.Error())
+}
+
+type overflowingDefaultValueMarker struct {
+	Size  int8   `parameter:"Size" default:"300"`
+	Limit uint   `parameter:"Limit" default:"-1"`
+	Sizes []int8 `parameter:"Sizes" default:"{1, 128}"`
+}
+
+func TestExtractArgument_OverflowingDefaultValue(t *testing.T) {
+	testCases := map[string]string{
+		"Size":  "default value of argument 'Size' cannot be parsed: integer 300 overflows int8",
+		"Limit": "default value of argument 'Limit' cannot be parsed: integer -1 overflows uint",
+		"Sizes": "default value of argument 'Sizes' cannot be parsed: integer 128 overflows int8",
+	}
+
+	for name, message := range testCases {
+		field, _ := reflect.TypeOf(overflowingDefaultValueMarker{}).FieldByName(name)
+		_, err := ExtractArgument(field)
+		assert.EqualError(t, err, message, name)
+	}
+}
+
+type integerMarker struct {
+	Count int8   `parameter:"Count"`
+	Limit uint16 `parameter:"Limit"`
+}
+
+func TestDefinition_ParseShouldReturnErrorIfIntegerOverflows(t *testing.T) {
+	definition, err := MakeDefinition("test:integer", "anyPkg", FieldLevel, &integerMarker{})
+	assert.NoError(t, err)
+
+	_, err = definition.Parse("+test:integer:Count=300")
+	assert.ErrorContains(t, err, "integer 300 overflows int8")
+
+	_, err = definition.Parse("+test:integer:Limit=-1")
+	assert.ErrorContains(t, err, "integer -1 overflows uint16")
+
+	_, err = definition.Parse("+test:integer:Limit=70000")
+	assert.ErrorContains(t, err, "integer 70000 overflows uint16")
+
+	value, err := definition.Parse("+test:integer:Count=-128, Limit=65535")
+	assert.NoError(t, err)
+	assert.Equal(t, integerMarker{Count: -128, Limit: 65535}, value)
+}
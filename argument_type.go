@@ -3,9 +3,12 @@ package markers
 import (
 	"errors"
 	"fmt"
+	"go/token"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type ArgumentType int
@@ -26,6 +29,10 @@ const (
 	MapType
 	GoFuncType
 	GoType
+	FloatType
+	DurationType
+	TimeType
+	StructType
 )
 
 var argumentTypeText = map[ArgumentType]string{
@@ -40,11 +47,18 @@ var argumentTypeText = map[ArgumentType]string{
 	MapType:             "MapType",
 	GoFuncType:          "FuncType",
 	GoType:              "Type",
+	FloatType:           "FloatType",
+	DurationType:        "DurationType",
+	TimeType:            "TimeType",
+	StructType:          "StructType",
 }
 
 var (
 	anyType = reflect.TypeOf((*any)(nil)).Elem()
 	rawType = reflect.TypeOf((*[]byte)(nil)).Elem()
+
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
 )
 
 type ArgumentTypeInfo struct {
@@ -52,9 +66,18 @@ type ArgumentTypeInfo struct {
 	IsPointer  bool
 	ItemType   *ArgumentTypeInfo
 	Enum       map[string]any
+	// Fields and FieldNames are only available for the struct types.
+	Fields     map[string]Argument
+	FieldNames map[string]string
 }
 
 func ArgumentTypeInfoFromType(typ reflect.Type) (ArgumentTypeInfo, error) {
+	return argumentTypeInfoFromType(typ, make(map[reflect.Type]bool))
+}
+
+// argumentTypeInfoFromType returns the type info of the given type, the struct types which are
+// being expanded are tracked in visiting to reject the recursive types.
+func argumentTypeInfoFromType(typ reflect.Type, visiting map[reflect.Type]bool) (ArgumentTypeInfo, error) {
 	typeInfo := &ArgumentTypeInfo{
 		Enum: map[string]any{},
 	}
@@ -74,6 +97,26 @@ func ArgumentTypeInfoFromType(typ reflect.Type) (ArgumentTypeInfo, error) {
 		return *typeInfo, nil
 	}
 
+	if typ == durationType {
+		typeInfo.ActualType = DurationType
+		return *typeInfo, nil
+	}
+
+	if typ == timeType {
+		typeInfo.ActualType = TimeType
+		return *typeInfo, nil
+	}
+
+	if typ == typeReferenceType {
+		typeInfo.ActualType = GoType
+		return *typeInfo, nil
+	}
+
+	if typ == funcReferenceType {
+		typeInfo.ActualType = GoFuncType
+		return *typeInfo, nil
+	}
+
 	switch typ.Kind() {
 	case reflect.String:
 		typeInfo.ActualType = StringType
@@ -81,11 +124,13 @@ func ArgumentTypeInfoFromType(typ reflect.Type) (ArgumentTypeInfo, error) {
 		typeInfo.ActualType = UnsignedIntegerType
 	case reflect.Int8, reflect.Int16, reflect.Int, reflect.Int32, reflect.Int64:
 		typeInfo.ActualType = SignedIntegerType
+	case reflect.Float32, reflect.Float64:
+		typeInfo.ActualType = FloatType
 	case reflect.Bool:
 		typeInfo.ActualType = BoolType
 	case reflect.Slice:
 		typeInfo.ActualType = SliceType
-		itemType, err := ArgumentTypeInfoFromType(typ.Elem())
+		itemType, err := argumentTypeInfoFromType(typ.Elem(), visiting)
 
 		if err != nil {
 			return ArgumentTypeInfo{}, fmt.Errorf("bad slice item type: %w", err)
@@ -98,13 +143,41 @@ func ArgumentTypeInfoFromType(typ reflect.Type) (ArgumentTypeInfo, error) {
 		}
 
 		typeInfo.ActualType = MapType
-		itemType, err := ArgumentTypeInfoFromType(typ.Elem())
+		itemType, err := argumentTypeInfoFromType(typ.Elem(), visiting)
 
 		if err != nil {
 			return ArgumentTypeInfo{}, fmt.Errorf("bad map item type: %w", err)
 		}
 
 		typeInfo.ItemType = &itemType
+	case reflect.Struct:
+		if visiting[typ] {
+			return ArgumentTypeInfo{}, fmt.Errorf("recursive type %s is not supported", typ)
+		}
+
+		visiting[typ] = true
+		defer delete(visiting, typ)
+
+		typeInfo.ActualType = StructType
+		typeInfo.Fields = make(map[string]Argument)
+		typeInfo.FieldNames = make(map[string]string)
+
+		for index := 0; index < typ.NumField(); index++ {
+			field := typ.Field(index)
+
+			if field.PkgPath != "" {
+				continue
+			}
+
+			argument, err := extractArgument(field, visiting)
+
+			if err != nil {
+				return ArgumentTypeInfo{}, fmt.Errorf("bad struct field '%s': %w", field.Name, err)
+			}
+
+			typeInfo.Fields[argument.Name] = argument
+			typeInfo.FieldNames[argument.Name] = field.Name
+		}
 	default:
 		return ArgumentTypeInfo{}, fmt.Errorf("type has unsupported kind %s", typ.Kind())
 	}
@@ -118,13 +191,22 @@ func (typeInfo ArgumentTypeInfo) Parse(scanner *Scanner, out reflect.Value) erro
 		return typeInfo.parseBoolean(scanner, out)
 	case SignedIntegerType, UnsignedIntegerType:
 		return typeInfo.parseInteger(scanner, out)
+	case FloatType:
+		return typeInfo.parseFloat(scanner, out)
+	case DurationType:
+		return typeInfo.parseDuration(scanner, out)
+	case TimeType:
+		return typeInfo.parseTime(scanner, out)
 	case StringType:
 		return typeInfo.parseString(scanner, out)
 	case SliceType:
 		return typeInfo.parseSlice(scanner, out)
 	case MapType:
 		return typeInfo.parseMap(scanner, out)
+	case StructType:
+		return typeInfo.parseStruct(scanner, out)
 	case GoFuncType, GoType:
+		return typeInfo.parseReference(scanner, out)
 	case AnyType:
 		inferredType, _ := typeInfo.inferType(scanner, out, false)
 		newOut := out
@@ -168,16 +250,57 @@ func (typeInfo ArgumentTypeInfo) Parse(scanner *Scanner, out reflect.Value) erro
 	return nil
 }
 
+// parseText parses the given text into a new value of the given type.
+func (typeInfo ArgumentTypeInfo) parseText(typ reflect.Type, text string) (any, error) {
+	valueType := typ
+
+	if valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+
+	var errs []error
+	scanner := NewScanner(text)
+	scanner.ErrorCallback = func(scanner *Scanner, message string) {
+		errs = append(errs, scanner.newError(message))
+	}
+	scanner.Peek()
+
+	out := reflect.New(valueType)
+	err := typeInfo.Parse(scanner, out)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if character := scanner.SkipWhitespaces(); character != EOF {
+		errs = append(errs, ScannerError{
+			Message: fmt.Sprintf("unexpected character %q", character),
+			Offset:  scanner.SearchIndex(),
+		})
+	}
+
+	if len(errs) != 0 {
+		return nil, NewErrorList(errs)
+	}
+
+	if typ.Kind() == reflect.Ptr {
+		return out.Interface(), nil
+	}
+
+	return out.Elem().Interface(), nil
+}
+
 func (typeInfo ArgumentTypeInfo) setValue(out, value reflect.Value) {
-	outType := out.Type()
+	for out.Kind() == reflect.Ptr && out.Type() != value.Type() {
+		if out.IsNil() {
+			out.Set(reflect.New(out.Type().Elem()))
+		}
 
-	if outType.Kind() == reflect.Ptr {
-		outType = outType.Elem()
 		out = out.Elem()
 	}
 
-	if outType != value.Type() {
-		value = value.Convert(outType)
+	if out.Type() != value.Type() {
+		value = value.Convert(out.Type())
 	}
 
 	out.Set(value)
@@ -189,7 +312,7 @@ func (typeInfo ArgumentTypeInfo) parseBoolean(scanner *Scanner, out reflect.Valu
 	}
 
 	if !scanner.Expect(Identifier, "Boolean (true or false)") {
-		return nil
+		return fmt.Errorf("expected true or false, got %q", scanner.Token())
 	}
 
 	switch scanner.Token() {
@@ -223,7 +346,7 @@ func (typeInfo ArgumentTypeInfo) parseInteger(scanner *Scanner, out reflect.Valu
 	}
 
 	if !scanner.Expect(IntegerValue, "Integer") {
-		return nil
+		return fmt.Errorf("expected integer, got %q", scanner.Token())
 	}
 
 	text := scanner.Token()
@@ -232,17 +355,152 @@ func (typeInfo ArgumentTypeInfo) parseInteger(scanner *Scanner, out reflect.Valu
 		text = "-" + text
 	}
 
-	intValue, err := strconv.Atoi(text)
+	outType := out.Type()
+
+	for outType.Kind() == reflect.Ptr {
+		outType = outType.Elem()
+	}
+
+	var value reflect.Value
+
+	switch outType.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		uintValue, err := strconv.ParseUint(text, 10, outType.Bits())
+
+		if isNegative || errors.Is(err, strconv.ErrRange) {
+			return fmt.Errorf("integer %s overflows %s", text, outType)
+		}
+
+		if err != nil {
+			return fmt.Errorf("unable to parse integer: %v", err)
+		}
+
+		value = reflect.ValueOf(uintValue)
+	case reflect.Int8, reflect.Int16, reflect.Int, reflect.Int32, reflect.Int64:
+		intValue, err := strconv.ParseInt(text, 10, outType.Bits())
 
-	typeInfo.setValue(out, reflect.ValueOf(intValue))
+		if errors.Is(err, strconv.ErrRange) {
+			return fmt.Errorf("integer %s overflows %s", text, outType)
+		}
+
+		if err != nil {
+			return fmt.Errorf("unable to parse integer: %v", err)
+		}
+
+		value = reflect.ValueOf(intValue)
+	default:
+		intValue, err := strconv.Atoi(text)
+
+		if err != nil {
+			return fmt.Errorf("unable to parse integer: %v", err)
+		}
+
+		value = reflect.ValueOf(intValue)
+	}
+
+	typeInfo.setValue(out, value)
+	return nil
+}
+
+func (typeInfo ArgumentTypeInfo) parseFloat(scanner *Scanner, out reflect.Value) error {
+	if scanner == nil {
+		return errors.New("scanner cannot be nil")
+	}
+
+	text, err := scanLiteral(scanner, ",;:}")
+
+	if err != nil {
+		return err
+	}
+
+	outType := out.Type()
+
+	if outType.Kind() == reflect.Ptr {
+		outType = outType.Elem()
+	}
+
+	bitSize := 64
+
+	if outType.Kind() == reflect.Float32 {
+		bitSize = 32
+	}
+
+	floatValue, err := strconv.ParseFloat(text, bitSize)
 
 	if err != nil {
-		return fmt.Errorf("unable to parse integer: %v", err)
+		return fmt.Errorf("expected float, got %q", text)
 	}
 
+	typeInfo.setValue(out, reflect.ValueOf(floatValue))
 	return nil
 }
 
+func (typeInfo ArgumentTypeInfo) parseDuration(scanner *Scanner, out reflect.Value) error {
+	if scanner == nil {
+		return errors.New("scanner cannot be nil")
+	}
+
+	text, err := scanLiteral(scanner, ",;:}")
+
+	if err != nil {
+		return err
+	}
+
+	duration, err := time.ParseDuration(text)
+
+	if err != nil {
+		return fmt.Errorf("expected duration, got %q", text)
+	}
+
+	typeInfo.setValue(out, reflect.ValueOf(duration))
+	return nil
+}
+
+func (typeInfo ArgumentTypeInfo) parseTime(scanner *Scanner, out reflect.Value) error {
+	if scanner == nil {
+		return errors.New("scanner cannot be nil")
+	}
+
+	// the time values contain colons, they cannot be used as delimiters
+	text, err := scanLiteral(scanner, ",;}")
+
+	if err != nil {
+		return err
+	}
+
+	timeValue, err := time.Parse(time.RFC3339, text)
+
+	if err != nil {
+		return fmt.Errorf("expected RFC3339 time, got %q", text)
+	}
+
+	typeInfo.setValue(out, reflect.ValueOf(timeValue))
+	return nil
+}
+
+// scanLiteral scans a literal value which cannot be tokenized such as 2.5, 30s
+// or 2006-01-02T15:04:05Z. It stops at whitespaces and the given delimiters.
+// The quoted literals are unquoted.
+func scanLiteral(scanner *Scanner, delimiters string) (string, error) {
+	character := scanner.SkipWhitespaces()
+
+	if character == '"' || character == '`' {
+		scanner.Scan()
+		return strconv.Unquote(scanner.Token())
+	}
+
+	startPosition := scanner.searchIndex
+
+	for character != EOF && !strings.ContainsRune(delimiters, character) && Whitespace&(1<<uint(character)) == 0 {
+		character = scanner.Next()
+	}
+
+	scanner.character = character
+	scanner.tokenStartPosition = startPosition
+	scanner.tokenEndPosition = scanner.searchIndex
+	return scanner.Token(), nil
+}
+
 func (typeInfo ArgumentTypeInfo) parseString(scanner *Scanner, out reflect.Value) error {
 	if scanner == nil {
 		return errors.New("scanner cannot be nil")
@@ -260,7 +518,7 @@ func (typeInfo ArgumentTypeInfo) parseString(scanner *Scanner, out reflect.Value
 			return err
 		}
 
-		typeInfo.setValue(out, reflect.ValueOf(value))
+		typeInfo.setStringValue(scanner, out, value)
 		return nil
 	}
 
@@ -272,12 +530,44 @@ func (typeInfo ArgumentTypeInfo) parseString(scanner *Scanner, out reflect.Value
 
 	value := string(scanner.source[startPosition:endPosition])
 	value = strings.TrimLeft(value, " \t")
+	scanner.tokenStartPosition = endPosition - len(value)
 	value = strings.TrimRight(value, " \t")
-	typeInfo.setValue(out, reflect.ValueOf(value))
+	scanner.tokenEndPosition = scanner.tokenStartPosition + len(value)
+	typeInfo.setStringValue(scanner, out, value)
 
 	return nil
 }
 
+// setStringValue sets the given string value. If the type has enum values, the value
+// is replaced with its mapped value, or an error is added unless it is one of them.
+func (typeInfo ArgumentTypeInfo) setStringValue(scanner *Scanner, out reflect.Value, value string) {
+	if len(typeInfo.Enum) == 0 {
+		typeInfo.setValue(out, reflect.ValueOf(value))
+		return
+	}
+
+	if enumValue, ok := typeInfo.Enum[value]; ok {
+		typeInfo.setValue(out, reflect.ValueOf(enumValue))
+		return
+	}
+
+	allowedValues := make([]string, 0, len(typeInfo.Enum))
+
+	for enumKey := range typeInfo.Enum {
+		allowedValues = append(allowedValues, enumKey)
+	}
+
+	sort.Strings(allowedValues)
+	message := fmt.Sprintf("invalid value %q, allowed values are %s", value, strings.Join(allowedValues, ", "))
+
+	if closest, ok := ClosestMatch(value, allowedValues); ok {
+		scanner.AddSuggestedError(fmt.Sprintf("%s; did you mean %q?", message, closest), closest)
+		return
+	}
+
+	scanner.AddError(message)
+}
+
 func (typeInfo ArgumentTypeInfo) parseSlice(scanner *Scanner, out reflect.Value) error {
 	if scanner == nil {
 		return errors.New("scanner cannot be nil")
@@ -292,6 +582,8 @@ func (typeInfo ArgumentTypeInfo) parseSlice(scanner *Scanner, out reflect.Value)
 	sliceItemType := reflect.Indirect(reflect.New(reflectVal.Type().Elem()))
 
 	if scanner.SkipWhitespaces() == '{' {
+		// the empty braces are parsed into an empty slice rather than nil
+		sliceType = reflect.MakeSlice(reflectVal.Type(), 0, 0)
 
 		scanner.Scan()
 
@@ -306,7 +598,7 @@ func (typeInfo ArgumentTypeInfo) parseSlice(scanner *Scanner, out reflect.Value)
 
 			token := scanner.SkipWhitespaces()
 
-			if token == '}' {
+			if token == '}' || token == EOF {
 				break
 			}
 
@@ -386,7 +678,7 @@ func (typeInfo ArgumentTypeInfo) parseMap(scanner *Scanner, out reflect.Value) e
 
 		mapType.SetMapIndex(key, value)
 
-		if scanner.SkipWhitespaces() == '}' {
+		if character = scanner.SkipWhitespaces(); character == '}' || character == EOF {
 			break
 		}
 
@@ -403,6 +695,90 @@ func (typeInfo ArgumentTypeInfo) parseMap(scanner *Scanner, out reflect.Value) e
 	return nil
 }
 
+func (typeInfo ArgumentTypeInfo) parseStruct(scanner *Scanner, out reflect.Value) error {
+	if scanner == nil {
+		return errors.New("scanner cannot be nil")
+	}
+
+	structType := out.Type()
+
+	for structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	structValue := reflect.New(structType).Elem()
+	seen := make(map[string]struct{}, len(typeInfo.Fields))
+
+	if !scanner.Expect('{', "Left Curly Bracket") {
+		return nil
+	}
+
+	for character := scanner.SkipWhitespaces(); character != '}' && character != EOF; character = scanner.SkipWhitespaces() {
+		if !scanner.Expect(Identifier, "Field name") {
+			return nil
+		}
+
+		fieldName := scanner.Token()
+		argument, exists := typeInfo.Fields[fieldName]
+
+		if !exists {
+			message := fmt.Sprintf("unknown field %q", fieldName)
+
+			if closest, ok := ClosestMatch(fieldName, sortedArgumentNames(typeInfo.Fields)); ok {
+				message = fmt.Sprintf("%s; did you mean %q?", message, closest)
+			}
+
+			scanner.AddError(message)
+		}
+
+		if !scanner.Expect(':', "Colon ':'") {
+			return nil
+		}
+
+		if exists {
+			err := argument.TypeInfo.Parse(scanner, structValue.FieldByName(typeInfo.FieldNames[fieldName]))
+
+			if err != nil {
+				return err
+			}
+
+			seen[fieldName] = struct{}{}
+		} else {
+			var anyValue any
+			(&ArgumentTypeInfo{ActualType: AnyType}).Parse(scanner, reflect.ValueOf(&anyValue))
+		}
+
+		if character = scanner.SkipWhitespaces(); character == '}' || character == EOF {
+			break
+		}
+
+		if !scanner.Expect(',', "Comma ','") {
+			return nil
+		}
+	}
+
+	if !scanner.Expect('}', "Right Curly Bracket '}'") {
+		return nil
+	}
+
+	for _, fieldName := range sortedArgumentNames(typeInfo.Fields) {
+		if _, wasSeen := seen[fieldName]; wasSeen {
+			continue
+		}
+
+		argument := typeInfo.Fields[fieldName]
+
+		if argument.Default != nil {
+			structValue.FieldByName(typeInfo.FieldNames[fieldName]).Set(copyValue(reflect.ValueOf(argument.Default)))
+		} else if argument.Required {
+			scanner.AddError(fmt.Sprintf("missing field %q", fieldName))
+		}
+	}
+
+	typeInfo.setValue(out, structValue)
+	return nil
+}
+
 func (typeInfo ArgumentTypeInfo) inferType(scanner *Scanner, out reflect.Value, ignoreLegacySlice bool) (ArgumentTypeInfo, error) {
 
 	character := scanner.SkipWhitespaces()
@@ -502,9 +878,8 @@ func (typeInfo ArgumentTypeInfo) inferType(scanner *Scanner, out reflect.Value,
 		}
 
 		if token == IntegerValue {
-			return ArgumentTypeInfo{
-				ActualType: SignedIntegerType,
-			}, nil
+			scanner.SetSearchIndex(searchIndex)
+			return inferNumericType(scanner), nil
 		}
 
 	}
@@ -514,6 +889,43 @@ func (typeInfo ArgumentTypeInfo) inferType(scanner *Scanner, out reflect.Value,
 	}, nil
 }
 
+// inferNumericType infers the type of the literal starting with a number.
+func inferNumericType(scanner *Scanner) ArgumentTypeInfo {
+	text, _ := scanLiteral(scanner, ",;}")
+
+	if _, err := time.Parse(time.RFC3339, text); err == nil {
+		return ArgumentTypeInfo{
+			ActualType: TimeType,
+		}
+	}
+
+	if index := strings.IndexRune(text, ':'); index != -1 {
+		text = text[:index]
+	}
+
+	if _, err := strconv.Atoi(text); err == nil {
+		return ArgumentTypeInfo{
+			ActualType: SignedIntegerType,
+		}
+	}
+
+	if _, err := strconv.ParseFloat(text, 64); err == nil {
+		return ArgumentTypeInfo{
+			ActualType: FloatType,
+		}
+	}
+
+	if _, err := time.ParseDuration(text); err == nil {
+		return ArgumentTypeInfo{
+			ActualType: DurationType,
+		}
+	}
+
+	return ArgumentTypeInfo{
+		ActualType: SignedIntegerType,
+	}
+}
+
 func (typeInfo ArgumentTypeInfo) makeSliceType() (reflect.Type, error) {
 	if typeInfo.ActualType != SliceType {
 		return nil, errors.New("this is not slice type")
@@ -533,6 +945,12 @@ func (typeInfo ArgumentTypeInfo) makeSliceType() (reflect.Type, error) {
 		itemType = reflect.TypeOf(false)
 	case StringType:
 		itemType = reflect.TypeOf("")
+	case FloatType:
+		itemType = reflect.TypeOf(float64(0))
+	case DurationType:
+		itemType = durationType
+	case TimeType:
+		itemType = timeType
 	case SliceType:
 		subItemType, err := typeInfo.ItemType.makeSliceType()
 
@@ -577,6 +995,12 @@ func (typeInfo ArgumentTypeInfo) makeMapType() (reflect.Type, error) {
 		itemType = reflect.TypeOf(false)
 	case StringType:
 		itemType = reflect.TypeOf("")
+	case FloatType:
+		itemType = reflect.TypeOf(float64(0))
+	case DurationType:
+		itemType = durationType
+	case TimeType:
+		itemType = timeType
 	case SliceType:
 		subItemType, err := typeInfo.ItemType.makeSliceType()
 
@@ -599,3 +1023,72 @@ func (typeInfo ArgumentTypeInfo) makeMapType() (reflect.Type, error) {
 
 	return reflect.MapOf(reflect.TypeOf(""), itemType), nil
 }
+
+// dynamicType returns the Go type which the values of the type are parsed into
+// when the definition does not have an output type.
+func (typeInfo ArgumentTypeInfo) dynamicType() (reflect.Type, error) {
+	switch typeInfo.ActualType {
+	case AnyType:
+		return anyType, nil
+	case BoolType:
+		return reflect.TypeOf(false), nil
+	case SignedIntegerType:
+		return reflect.TypeOf(0), nil
+	case UnsignedIntegerType:
+		return reflect.TypeOf(uint(0)), nil
+	case StringType:
+		return reflect.TypeOf(""), nil
+	case FloatType:
+		return reflect.TypeOf(float64(0)), nil
+	case DurationType:
+		return durationType, nil
+	case TimeType:
+		return timeType, nil
+	case GoType:
+		return typeReferenceType, nil
+	case GoFuncType:
+		return funcReferenceType, nil
+	case SliceType, MapType:
+		if typeInfo.ItemType == nil {
+			return nil, fmt.Errorf("item type cannot be nil for %v", typeInfo.ActualType)
+		}
+
+		itemType, err := typeInfo.ItemType.dynamicType()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if typeInfo.ActualType == SliceType {
+			return reflect.SliceOf(itemType), nil
+		}
+
+		return reflect.MapOf(reflect.TypeOf(""), itemType), nil
+	case StructType:
+		fields := make([]reflect.StructField, 0, len(typeInfo.Fields))
+
+		for _, argumentName := range sortedArgumentNames(typeInfo.Fields) {
+			fieldName := typeInfo.FieldNames[argumentName]
+
+			if !token.IsExported(fieldName) {
+				return nil, fmt.Errorf("field '%s' does not have an exported Go name", argumentName)
+			}
+
+			fieldType, err := typeInfo.Fields[argumentName].TypeInfo.dynamicType()
+
+			if err != nil {
+				return nil, fmt.Errorf("bad struct field '%s': %w", argumentName, err)
+			}
+
+			fields = append(fields, reflect.StructField{
+				Name: fieldName,
+				Type: fieldType,
+				Tag:  reflect.StructTag(fmt.Sprintf("parameter:%q", argumentName)),
+			})
+		}
+
+		return reflect.StructOf(fields), nil
+	}
+
+	return nil, fmt.Errorf("invalid type: %v", typeInfo.ActualType)
+}
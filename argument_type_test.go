@@ -4,6 +4,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestGetArgumentTypeInfo(t *testing.T) {
@@ -119,6 +120,11 @@ func TestGetArgumentTypeInfo(t *testing.T) {
 		{
 			Type: reflect.TypeOf(&struct {
 			}{}),
+			ShouldReturnError: false,
+			ExpectedType:      StructType,
+		},
+		{
+			Type:              reflect.TypeOf(make(chan int)),
 			ShouldReturnError: true,
 			ExpectedType:      InvalidType,
 		},
@@ -372,6 +378,248 @@ func TestArgumentTypeInfo_ParseSlice(t *testing.T) {
 	assert.Equal(t, []int{1, 2, 3, 4, 5}, s)
 }
 
+func TestArgumentTypeInfo_ParseFloat(t *testing.T) {
+	typeInfo, err := ArgumentTypeInfoFromType(reflect.TypeOf(float64(0)))
+	assert.Nil(t, err)
+	assert.Equal(t, FloatType, typeInfo.ActualType)
+
+	floatValue := 0.0
+
+	scanner := NewScanner(" -2.5 ")
+	scanner.Peek()
+
+	err = typeInfo.Parse(scanner, reflect.ValueOf(&floatValue))
+	assert.Nil(t, err)
+	assert.Equal(t, -2.5, floatValue)
+
+	scanner = NewScanner(" 1e3,")
+	scanner.Peek()
+
+	err = typeInfo.Parse(scanner, reflect.ValueOf(&floatValue))
+	assert.Nil(t, err)
+	assert.Equal(t, 1000.0, floatValue)
+	assert.Equal(t, ',', scanner.Peek())
+
+	var float32Value float32
+	typeInfo, err = ArgumentTypeInfoFromType(reflect.TypeOf(&float32Value))
+	assert.Nil(t, err)
+	assert.Equal(t, FloatType, typeInfo.ActualType)
+
+	scanner = NewScanner("0.25")
+	scanner.Peek()
+
+	err = typeInfo.Parse(scanner, reflect.ValueOf(&float32Value))
+	assert.Nil(t, err)
+	assert.Equal(t, float32(0.25), float32Value)
+
+	scanner = NewScanner("2.5.1")
+	scanner.Peek()
+
+	err = typeInfo.Parse(scanner, reflect.ValueOf(&float32Value))
+	assert.EqualError(t, err, "expected float, got \"2.5.1\"")
+}
+
+func TestArgumentTypeInfo_ParseDuration(t *testing.T) {
+	typeInfo, err := ArgumentTypeInfoFromType(reflect.TypeOf(time.Duration(0)))
+	assert.Nil(t, err)
+	assert.Equal(t, DurationType, typeInfo.ActualType)
+
+	var duration time.Duration
+
+	scanner := NewScanner(" 1h30m ")
+	scanner.Peek()
+
+	err = typeInfo.Parse(scanner, reflect.ValueOf(&duration))
+	assert.Nil(t, err)
+	assert.Equal(t, 90*time.Minute, duration)
+
+	scanner = NewScanner("\"30s\"")
+	scanner.Peek()
+
+	err = typeInfo.Parse(scanner, reflect.ValueOf(&duration))
+	assert.Nil(t, err)
+	assert.Equal(t, 30*time.Second, duration)
+
+	scanner = NewScanner("30")
+	scanner.Peek()
+
+	err = typeInfo.Parse(scanner, reflect.ValueOf(&duration))
+	assert.EqualError(t, err, "expected duration, got \"30\"")
+}
+
+func TestArgumentTypeInfo_ParseTime(t *testing.T) {
+	typeInfo, err := ArgumentTypeInfoFromType(reflect.TypeOf(&time.Time{}))
+	assert.Nil(t, err)
+	assert.Equal(t, TimeType, typeInfo.ActualType)
+	assert.True(t, typeInfo.IsPointer)
+
+	var timeValue *time.Time
+
+	scanner := NewScanner(" 2022-03-04T10:20:30Z, ")
+	scanner.Peek()
+
+	err = typeInfo.Parse(scanner, reflect.ValueOf(&timeValue))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2022, 3, 4, 10, 20, 30, 0, time.UTC), *timeValue)
+	assert.Equal(t, ',', scanner.Peek())
+
+	scanner = NewScanner("2022-03-04")
+	scanner.Peek()
+
+	err = typeInfo.Parse(scanner, reflect.ValueOf(&timeValue))
+	assert.EqualError(t, err, "expected RFC3339 time, got \"2022-03-04\"")
+}
+
+func TestArgumentTypeInfo_NumericTypeInference(t *testing.T) {
+	var value any
+	typeInfo, err := ArgumentTypeInfoFromType(reflect.TypeOf(&value))
+	assert.Nil(t, err)
+
+	testCases := []struct {
+		Text     string
+		Expected any
+	}{
+		{Text: "23", Expected: 23},
+		{Text: "-2.5", Expected: -2.5},
+		{Text: "30s", Expected: 30 * time.Second},
+		{Text: "2022-03-04T10:20:30Z", Expected: time.Date(2022, 3, 4, 10, 20, 30, 0, time.UTC)},
+		{Text: "{1.5, 2}", Expected: []any{1.5, 2}},
+		{Text: "{ttl: 1m, rps: 2.5}", Expected: map[string]any{"ttl": time.Minute, "rps": 2.5}},
+	}
+
+	for _, testCase := range testCases {
+		scanner := NewScanner(testCase.Text)
+		scanner.Peek()
+
+		err = typeInfo.Parse(scanner, reflect.ValueOf(&value))
+		assert.Nil(t, err, testCase.Text)
+		assert.Equal(t, testCase.Expected, value, testCase.Text)
+	}
+}
+
+type indexColumn struct {
+	Name  string `parameter:"Name" required:"true"`
+	Order string `parameter:"Order" enum:"asc,desc" default:"asc"`
+	Size  *int   `parameter:"Size"`
+}
+
+type recursiveNode struct {
+	Name string
+	Next *recursiveNode
+}
+
+type recursiveTree struct {
+	Name     string
+	Children []recursiveTree
+}
+
+type recursiveGraph struct {
+	Edges map[string]recursiveGraph
+}
+
+type recursiveMarker struct {
+	Root recursiveNode
+}
+
+type repeatedStructMarker struct {
+	Primary   indexColumn
+	Secondary []indexColumn
+}
+
+func TestArgumentTypeInfoFromType_ShouldReturnErrorIfTypeIsRecursive(t *testing.T) {
+	_, err := ArgumentTypeInfoFromType(reflect.TypeOf(recursiveNode{}))
+	assert.EqualError(t, err, "bad struct field 'Next': recursive type markers.recursiveNode is not supported")
+
+	_, err = ArgumentTypeInfoFromType(reflect.TypeOf([]recursiveTree{}))
+	assert.EqualError(t, err, "bad slice item type: bad struct field 'Children': bad slice item type: recursive type markers.recursiveTree is not supported")
+
+	_, err = ArgumentTypeInfoFromType(reflect.TypeOf(recursiveGraph{}))
+	assert.EqualError(t, err, "bad struct field 'Edges': bad map item type: recursive type markers.recursiveGraph is not supported")
+
+	_, err = MakeDefinition("test:recursive", "github.com/procyon-projects/test", FieldLevel, &recursiveMarker{})
+	assert.Error(t, err)
+
+	typeInfo, err := ArgumentTypeInfoFromType(reflect.TypeOf(repeatedStructMarker{}))
+	assert.Nil(t, err)
+	assert.Equal(t, StructType, typeInfo.Fields["Primary"].TypeInfo.ActualType)
+	assert.Equal(t, StructType, typeInfo.Fields["Secondary"].TypeInfo.ItemType.ActualType)
+}
+
+func TestArgumentTypeInfo_ParseStruct(t *testing.T) {
+	typeInfo, err := ArgumentTypeInfoFromType(reflect.TypeOf([]indexColumn{}))
+	assert.Nil(t, err)
+	assert.Equal(t, SliceType, typeInfo.ActualType)
+	assert.Equal(t, StructType, typeInfo.ItemType.ActualType)
+	assert.Equal(t, map[string]string{"Name": "Name", "Order": "Order", "Size": "Size"}, typeInfo.ItemType.FieldNames)
+
+	var columns []indexColumn
+
+	scanner := NewScanner(" {{Name: \"a\", Order: desc}, {Name: b, Size: 3}} ")
+	scanner.Peek()
+
+	err = typeInfo.Parse(scanner, reflect.ValueOf(&columns))
+	assert.Nil(t, err)
+
+	size := 3
+	assert.Equal(t, []indexColumn{{Name: "a", Order: "desc"}, {Name: "b", Order: "asc", Size: &size}}, columns)
+
+	testCases := []struct {
+		Text    string
+		Message string
+	}{
+		{Text: "{{Name: a, Nme: b}}", Message: "unknown field \"Nme\"; did you mean \"Name\"?"},
+		{Text: "{{Order: desc}}", Message: "missing field \"Name\""},
+		{Text: "{{Name: a, Order: des}}", Message: "invalid value \"des\", allowed values are asc, desc; did you mean \"desc\"?"},
+		{Text: "{{Name: a, Size: x}}", Message: "got \"x\"; want Integer"},
+	}
+
+	for _, testCase := range testCases {
+		var messages []string
+		scanner = NewScanner(testCase.Text)
+		scanner.ErrorCallback = func(scanner *Scanner, message string) {
+			messages = append(messages, message)
+		}
+		scanner.Peek()
+
+		_ = typeInfo.Parse(scanner, reflect.ValueOf(&columns))
+		assert.Equal(t, []string{testCase.Message}, messages, testCase.Text)
+	}
+}
+
+func TestArgumentTypeInfo_ParseReference(t *testing.T) {
+	typeInfo, err := ArgumentTypeInfoFromType(reflect.TypeOf(TypeReference{}))
+	assert.Nil(t, err)
+	assert.Equal(t, GoType, typeInfo.ActualType)
+
+	var typeReference TypeReference
+
+	scanner := NewScanner(" time.Time ")
+	scanner.Peek()
+
+	err = typeInfo.Parse(scanner, reflect.ValueOf(&typeReference))
+	assert.Nil(t, err)
+	assert.Equal(t, TypeReference{Package: "time", Name: "Time", offset: 1}, typeReference)
+
+	typeInfo, err = ArgumentTypeInfoFromType(reflect.TypeOf(&FuncReference{}))
+	assert.Nil(t, err)
+	assert.Equal(t, GoFuncType, typeInfo.ActualType)
+
+	var funcReference *FuncReference
+
+	scanner = NewScanner("NewService")
+	scanner.Peek()
+
+	err = typeInfo.Parse(scanner, reflect.ValueOf(&funcReference))
+	assert.Nil(t, err)
+	assert.Equal(t, &FuncReference{Name: "NewService"}, funcReference)
+
+	scanner = NewScanner("time.")
+	scanner.Peek()
+
+	err = typeInfo.Parse(scanner, reflect.ValueOf(&funcReference))
+	assert.EqualError(t, err, "expected identifier, got \"\"")
+}
+
 func TestArgumentTypeInfo_TypeInference(t *testing.T) {
 	var value any
 	typeInfo, err := ArgumentTypeInfoFromType(reflect.TypeOf(&value))
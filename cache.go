@@ -0,0 +1,547 @@
+package markers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/procyon-projects/marker/packages"
+	"go/ast"
+	"go/token"
+	"hash"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// collectionCacheVersion is changed whenever the format of the cache entries
+// or the way the markers are parsed is changed.
+const collectionCacheVersion = "2"
+
+// CollectionCacheDirEnv is the environment variable which overrides the default collection cache directory.
+const CollectionCacheDirEnv = "MARKERCACHE"
+
+// CollectionCache stores the marker values collected from the files on disk. The entries are keyed by
+// the content of the files and the fingerprint of the registry, so the files which are not changed are
+// not walked and parsed again, and all the entries are invalidated when the definitions are changed.
+type CollectionCache struct {
+	dir string
+}
+
+// NewCollectionCache returns a collection cache storing its entries in the given directory.
+func NewCollectionCache(dir string) (*CollectionCache, error) {
+	err := os.MkdirAll(dir, os.ModePerm)
+
+	if err != nil {
+		return nil, fmt.Errorf("cache directory '%s' cannot be created: %w", dir, err)
+	}
+
+	return &CollectionCache{
+		dir: dir,
+	}, nil
+}
+
+// DefaultCollectionCacheDir returns the directory specified by the MARKERCACHE environment variable,
+// or the marker directory in the user cache directory.
+func DefaultCollectionCacheDir() (string, error) {
+	if dir := os.Getenv(CollectionCacheDirEnv); dir != "" {
+		return dir, nil
+	}
+
+	cacheDir, err := os.UserCacheDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, "marker"), nil
+}
+
+// Dir returns the directory of the cache.
+func (cache *CollectionCache) Dir() string {
+	return cache.dir
+}
+
+// Clear removes all the entries in the cache.
+func (cache *CollectionCache) Clear() error {
+	err := os.RemoveAll(cache.dir)
+
+	if err != nil {
+		return err
+	}
+
+	return os.MkdirAll(cache.dir, os.ModePerm)
+}
+
+// key returns the key of the entry for the file with the given content.
+func (cache *CollectionCache) key(fingerprint string, content []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(collectionCacheVersion + "\x00" + fingerprint + "\x00"))
+	hash.Write(content)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (cache *CollectionCache) entryPath(key string) string {
+	return filepath.Join(cache.dir, key[:2], key+".json")
+}
+
+func (cache *CollectionCache) load(key string) (*collectionCacheEntry, bool) {
+	data, err := os.ReadFile(cache.entryPath(key))
+
+	if err != nil {
+		return nil, false
+	}
+
+	entry := &collectionCacheEntry{}
+
+	if json.Unmarshal(data, entry) != nil {
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// store writes the entry into a temporary file first so that the incomplete
+// entries are never loaded.
+func (cache *CollectionCache) store(key string, entry *collectionCacheEntry) error {
+	data, err := json.Marshal(entry)
+
+	if err != nil {
+		return err
+	}
+
+	entryPath := cache.entryPath(key)
+	err = os.MkdirAll(filepath.Dir(entryPath), os.ModePerm)
+
+	if err != nil {
+		return err
+	}
+
+	var file *os.File
+	file, err = os.CreateTemp(filepath.Dir(entryPath), key+".*.tmp")
+
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(data)
+	closeErr := file.Close()
+
+	if err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(file.Name(), entryPath)
+	}
+
+	if err != nil {
+		os.Remove(file.Name())
+	}
+
+	return err
+}
+
+// collectionCacheEntry contains the marker values collected from a file.
+type collectionCacheEntry struct {
+	Nodes    []cachedNode    `json:"nodes"`
+	Warnings []cachedWarning `json:"warnings,omitempty"`
+}
+
+// cachedNode contains the marker values of a node, the node is found by its kind and offsets.
+type cachedNode struct {
+	Kind    string         `json:"kind"`
+	Offset  int            `json:"offset"`
+	End     int            `json:"end"`
+	Markers []cachedMarker `json:"markers"`
+}
+
+type cachedMarker struct {
+	Name    string `json:"name"`
+	Package string `json:"package"`
+	// Offset and Position are the offset and the position of the marker comment.
+	Offset    int              `json:"offset"`
+	Position  Position         `json:"position"`
+	Value     json.RawMessage  `json:"value,omitempty"`
+	Arguments []cachedArgument `json:"arguments,omitempty"`
+}
+
+// cachedArgument is an argument of a dynamic marker value.
+type cachedArgument struct {
+	Name      string          `json:"name"`
+	Value     json.RawMessage `json:"value"`
+	Offset    int             `json:"offset"`
+	Position  Position        `json:"position"`
+	IsDefault bool            `json:"isDefault,omitempty"`
+}
+
+type cachedWarning struct {
+	Position Position `json:"position"`
+	Message  string   `json:"message"`
+}
+
+// encodeCachedMarker returns the cached marker of the given value. It reports false if the value
+// cannot be decoded into an equal value, such as the values having references or inferred types.
+func (definition *Definition) encodeCachedMarker(value any) (cachedMarker, bool) {
+	marker := cachedMarker{
+		Name:    definition.Name,
+		Package: definition.Package,
+	}
+
+	if definition.hasReferences() {
+		return marker, false
+	}
+
+	var err error
+
+	if dynamicValue, isDynamic := value.(*DynamicValue); isDynamic {
+		for _, argument := range dynamicValue.arguments {
+			cached := cachedArgument{
+				Name:      argument.Name,
+				Offset:    argument.Offset,
+				Position:  argument.Position,
+				IsDefault: argument.IsDefault,
+			}
+
+			cached.Value, err = json.Marshal(argument.Value)
+
+			if err != nil {
+				return marker, false
+			}
+
+			marker.Arguments = append(marker.Arguments, cached)
+		}
+	} else {
+		marker.Value, err = json.Marshal(value)
+
+		if err != nil {
+			return marker, false
+		}
+	}
+
+	decoded, err := definition.decodeCachedMarker(marker)
+	return marker, err == nil && reflect.DeepEqual(decoded, value)
+}
+
+// decodeCachedMarker returns the value of the given cached marker.
+func (definition *Definition) decodeCachedMarker(marker cachedMarker) (any, error) {
+	if !definition.Output.IsDynamic {
+		out := reflect.New(definition.Output.Type)
+		err := json.Unmarshal(marker.Value, out.Interface())
+		return out.Elem().Interface(), err
+	}
+
+	value := newDynamicValue()
+
+	for _, argument := range marker.Arguments {
+		if _, exists := definition.Output.Fields[argument.Name]; !exists {
+			return nil, fmt.Errorf("argument '%s' does not exist", argument.Name)
+		}
+
+		argumentType, err := definition.argumentType(argument.Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		out := reflect.New(argumentType)
+		err = json.Unmarshal(argument.Value, out.Interface())
+
+		if err != nil {
+			return nil, err
+		}
+
+		value.set(DynamicArgument{
+			Name:      argument.Name,
+			Value:     out.Elem().Interface(),
+			Offset:    argument.Offset,
+			Position:  argument.Position,
+			IsDefault: argument.IsDefault,
+		})
+	}
+
+	return value, nil
+}
+
+// registryFingerprint returns the fingerprint of the definitions in the registry. It is changed
+// whenever a definition or the Go type of its output is changed.
+func registryFingerprint(registry *Registry) string {
+	hash := sha256.New()
+
+	for _, definition := range registry.Definitions() {
+		data, _ := json.Marshal(definition.Catalog())
+		hash.Write(data)
+		fmt.Fprintf(hash, "%t\x00", definition.AllowUnknownArguments)
+
+		if definition.Output.Type != nil {
+			writeTypeDescription(hash, definition.Output.Type, make(map[reflect.Type]bool))
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// writeTypeDescription writes the description of the type including the fields of the structs,
+// which are used while the values are encoded.
+func writeTypeDescription(hash hash.Hash, typ reflect.Type, visited map[reflect.Type]bool) {
+	fmt.Fprintf(hash, "%s.%s:%s\x00", typ.PkgPath(), typ.String(), typ.Kind())
+
+	if visited[typ] {
+		return
+	}
+
+	visited[typ] = true
+
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		writeTypeDescription(hash, typ.Elem(), visited)
+	case reflect.Map:
+		writeTypeDescription(hash, typ.Key(), visited)
+		writeTypeDescription(hash, typ.Elem(), visited)
+	case reflect.Struct:
+		for index := 0; index < typ.NumField(); index++ {
+			field := typ.Field(index)
+			fmt.Fprintf(hash, "%s `%s`\x00", field.Name, field.Tag)
+			writeTypeDescription(hash, field.Type, visited)
+		}
+	}
+}
+
+// collectWithCache restores the markers of the files which are not changed from the cache, and
+// stores the markers of the other files into the cache once they are all collected without any error.
+func (collector *Collector) collectWithCache(pkg *packages.Package) (map[ast.Node][]Marker, error) {
+	fingerprint := registryFingerprint(collector.Registry)
+	record := newCollectionRecord(pkg.Fset)
+	keys := make(map[*token.File]string)
+	nodeMarkers := make(map[ast.Node][]markerComment)
+	cachedMarkers := make(map[ast.Node][]Marker)
+
+	var errs []error
+
+	for _, file := range pkg.Syntax {
+		tokenFile := pkg.Fset.File(file.Pos())
+		content, err := os.ReadFile(tokenFile.Name())
+
+		// the file is collected without the cache if its content on disk
+		// does not belong to the parsed file.
+		if err == nil && len(content) == tokenFile.Size() {
+			key := collector.Cache.key(fingerprint, content)
+
+			if entry, exists := collector.Cache.load(key); exists {
+				fileMarkers, fileErrs, restored := collector.restoreCachedMarkers(pkg, file, entry)
+
+				if restored {
+					for node, markers := range fileMarkers {
+						cachedMarkers[node] = markers
+					}
+
+					errs = append(errs, fileErrs...)
+					continue
+				}
+			}
+
+			keys[tokenFile] = key
+			record.entries[tokenFile] = &collectionCacheEntry{}
+		}
+
+		for node, markers := range collector.collectFileMarkerComments(file) {
+			nodeMarkers[node] = append(nodeMarkers[node], markers...)
+		}
+	}
+
+	markers, err := collector.parseMarkerComments(pkg, nodeMarkers, record)
+	errs = appendError(errs, err)
+
+	if markers == nil {
+		markers = make(map[ast.Node][]Marker)
+	}
+
+	for node, nodeMarkers := range cachedMarkers {
+		markers[node] = nodeMarkers
+	}
+
+	if len(errs) != 0 {
+		return markers, NewErrorList(errs)
+	}
+
+	// the cache only speeds up the collection, the markers are
+	// collected again if they cannot be stored.
+	for tokenFile, key := range keys {
+		if entry := record.entry(tokenFile); entry != nil {
+			_ = collector.Cache.store(key, entry)
+		}
+	}
+
+	return markers, nil
+}
+
+// restoreCachedMarkers returns the markers in the cache entry of the given file. The markers are
+// validated again since the validation depends on the other files in the package. It reports false
+// if the entry does not match the file or the registry.
+func (collector *Collector) restoreCachedMarkers(pkg *packages.Package, file *ast.File, entry *collectionCacheEntry) (map[ast.Node][]Marker, []error, bool) {
+	tokenFile := pkg.Fset.File(file.Pos())
+	nodeIndex := cachedNodeIndex(file, tokenFile)
+	nodeMarkers := make(map[ast.Node][]Marker)
+
+	var errs []error
+
+	for _, cached := range entry.Nodes {
+		node, exists := nodeIndex[cachedNodeKey(cached.Kind, cached.Offset, cached.End)]
+
+		if !exists {
+			return nil, nil, false
+		}
+
+		var markers []Marker
+		targetLevel := FindTargetLevelFromNode(node)
+
+		for _, marker := range cached.Markers {
+			definition, exists := collector.Lookup(marker.Name, marker.Package, targetLevel)
+
+			if !exists {
+				return nil, nil, false
+			}
+
+			value, err := definition.decodeCachedMarker(marker)
+
+			if err != nil {
+				return nil, nil, false
+			}
+
+			err = validateMarker(value, ValidationContext{
+				Node:        node,
+				TargetLevel: targetLevel,
+				Package:     pkg,
+			})
+
+			position := token.Position{
+				Filename: tokenFile.Name(),
+				Offset:   marker.Offset,
+				Line:     marker.Position.Line,
+				Column:   marker.Position.Column,
+			}
+
+			if err != nil {
+				errs = appendError(errs, toParseError(err, node, position))
+				continue
+			}
+
+			markers = append(markers, Marker{
+				Name:     definition.Name,
+				Value:    value,
+				Position: position,
+			})
+		}
+
+		if len(markers) != 0 {
+			nodeMarkers[node] = markers
+		}
+	}
+
+	for _, warning := range entry.Warnings {
+		collector.warn(Warning{
+			FileName: tokenFile.Name(),
+			Position: warning.Position,
+			Message:  warning.Message,
+		})
+	}
+
+	return nodeMarkers, errs, true
+}
+
+// collectionRecord records the marker values collected from the files which are not found
+// in the cache, so that they can be stored in the cache.
+type collectionRecord struct {
+	fileSet *token.FileSet
+	entries map[*token.File]*collectionCacheEntry
+	// nodeIndexes contains the indexes of the nodes in their entries.
+	nodeIndexes map[ast.Node]int
+	// uncacheable contains the files having any marker value which cannot be cached.
+	uncacheable map[*token.File]bool
+}
+
+func newCollectionRecord(fileSet *token.FileSet) *collectionRecord {
+	return &collectionRecord{
+		fileSet:     fileSet,
+		entries:     make(map[*token.File]*collectionCacheEntry),
+		nodeIndexes: make(map[ast.Node]int),
+		uncacheable: make(map[*token.File]bool),
+	}
+}
+
+// add records the marker value of the given node with the warnings of the marker.
+func (record *collectionRecord) add(node ast.Node, definition *Definition, value any, position token.Position, warnings []Warning) {
+	if record == nil {
+		return
+	}
+
+	file := record.fileSet.File(node.Pos())
+	entry, exists := record.entries[file]
+
+	if !exists || record.uncacheable[file] {
+		return
+	}
+
+	marker, cacheable := definition.encodeCachedMarker(value)
+
+	if !cacheable {
+		record.uncacheable[file] = true
+		return
+	}
+
+	marker.Offset = position.Offset
+	marker.Position = Position{
+		Line:   position.Line,
+		Column: position.Column,
+	}
+
+	nodeIndex, exists := record.nodeIndexes[node]
+
+	if !exists {
+		nodeIndex = len(entry.Nodes)
+		record.nodeIndexes[node] = nodeIndex
+		entry.Nodes = append(entry.Nodes, cachedNode{
+			Kind:   fmt.Sprintf("%T", node),
+			Offset: file.Offset(node.Pos()),
+			End:    file.Offset(node.End()),
+		})
+	}
+
+	entry.Nodes[nodeIndex].Markers = append(entry.Nodes[nodeIndex].Markers, marker)
+
+	for _, warning := range warnings {
+		entry.Warnings = append(entry.Warnings, cachedWarning{
+			Position: warning.Position,
+			Message:  warning.Message,
+		})
+	}
+}
+
+// entry returns the recorded entry of the given file, or nil if it cannot be cached.
+func (record *collectionRecord) entry(file *token.File) *collectionCacheEntry {
+	if record.uncacheable[file] {
+		return nil
+	}
+
+	return record.entries[file]
+}
+
+// cachedNodeIndex returns the nodes in the file which can have markers by their kinds and offsets.
+func cachedNodeIndex(file *ast.File, tokenFile *token.File) map[string]ast.Node {
+	index := make(map[string]ast.Node)
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.File, *ast.GenDecl, *ast.TypeSpec, *ast.Field, *ast.FuncDecl:
+			index[cachedNodeKey(fmt.Sprintf("%T", node), tokenFile.Offset(node.Pos()), tokenFile.Offset(node.End()))] = node
+		}
+
+		return true
+	})
+
+	return index
+}
+
+func cachedNodeKey(kind string, offset, end int) string {
+	return fmt.Sprintf("%s:%d:%d", kind, offset, end)
+}
@@ -0,0 +1,140 @@
+package markers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const cacheTestSource = `
+// +import=test, Pkg=github.com/procyon-projects/test
+
+package source
+
+type User struct {
+	// +test:column:Name=name, Nullable=true
+	Name string
+}
+`
+
+func newCacheTestRegistry(t *testing.T) *Registry {
+	registry := NewRegistry()
+	columnDefinition, err := MakeDefinition("test:column", "github.com/procyon-projects/test", FieldLevel, &columnMarker{})
+	assert.NoError(t, err)
+	columnDefinition.Deprecated = true
+	assert.NoError(t, registry.RegisterWithDefinition(columnDefinition))
+	return registry
+}
+
+func writeCacheTestSource(t *testing.T, source string) string {
+	fileName := filepath.Join(t.TempDir(), "test.go")
+	assert.NoError(t, os.WriteFile(fileName, []byte(source), os.ModePerm))
+	return fileName
+}
+
+func cacheEntryFiles(t *testing.T, cache *CollectionCache) []string {
+	entryFiles, err := filepath.Glob(filepath.Join(cache.Dir(), "*", "*.json"))
+	assert.NoError(t, err)
+	return entryFiles
+}
+
+func collectWithCache(t *testing.T, registry *Registry, cache *CollectionCache, fileName, source string) (MarkerValues, []Warning) {
+	var warnings []Warning
+	collector := NewCollector(registry)
+	collector.Cache = cache
+	collector.WarningCallback = func(warning Warning) {
+		warnings = append(warnings, warning)
+	}
+
+	nodes, err := collector.Collect(newTestPackageFile(t, fileName, source))
+	assert.NoError(t, err)
+	return findMarkerValues(nodes, "Name"), warnings
+}
+
+func TestCollector_CollectShouldUseCache(t *testing.T) {
+	cache, err := NewCollectionCache(t.TempDir())
+	assert.NoError(t, err)
+
+	fileName := writeCacheTestSource(t, cacheTestSource)
+	markerValues, warnings := collectWithCache(t, newCacheTestRegistry(t), cache, fileName, cacheTestSource)
+	assert.Equal(t, columnMarker{Name: "name", Nullable: true}, markerValues.First("test:column"))
+	assert.Len(t, warnings, 2)
+
+	entryFiles := cacheEntryFiles(t, cache)
+	assert.Len(t, entryFiles, 1)
+
+	// the value in the entry is changed to make sure that the markers are restored from the cache.
+	data, err := os.ReadFile(entryFiles[0])
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(entryFiles[0], []byte(strings.Replace(string(data), `"Name":"name"`, `"Name":"cached"`, 1)), os.ModePerm))
+
+	var cachedWarnings []Warning
+	markerValues, cachedWarnings = collectWithCache(t, newCacheTestRegistry(t), cache, fileName, cacheTestSource)
+	assert.Equal(t, columnMarker{Name: "cached", Nullable: true}, markerValues.First("test:column"))
+	assert.Equal(t, warnings, cachedWarnings)
+	assert.Equal(t, fileName, cachedWarnings[0].FileName)
+	assert.Equal(t, Position{Line: 7, Column: 2}, cachedWarnings[0].Position)
+
+	collector := NewCollector(newCacheTestRegistry(t))
+	collector.Cache = cache
+	nodeMarkers, err := collector.CollectOrdered(newTestPackageFile(t, fileName, cacheTestSource))
+	assert.NoError(t, err)
+	assert.Len(t, nodeMarkers, 1)
+	assert.Equal(t, []Marker{
+		{Name: "test:column", Value: columnMarker{Name: "cached", Nullable: true}, Position: token.Position{Filename: fileName, Offset: 92, Line: 7, Column: 2}},
+	}, nodeMarkers[0].Markers)
+
+	assert.NoError(t, cache.Clear())
+	assert.Empty(t, cacheEntryFiles(t, cache))
+}
+
+func TestCollector_CollectShouldInvalidateCache(t *testing.T) {
+	cache, err := NewCollectionCache(t.TempDir())
+	assert.NoError(t, err)
+
+	fileName := writeCacheTestSource(t, cacheTestSource)
+	collectWithCache(t, newCacheTestRegistry(t), cache, fileName, cacheTestSource)
+	assert.Len(t, cacheEntryFiles(t, cache), 1)
+
+	registry := newCacheTestRegistry(t)
+	assert.NoError(t, registry.Register("test:index", "github.com/procyon-projects/test", StructTypeLevel, &indexMarker{}))
+	markerValues, _ := collectWithCache(t, registry, cache, fileName, cacheTestSource)
+	assert.Equal(t, columnMarker{Name: "name", Nullable: true}, markerValues.First("test:column"))
+	assert.Len(t, cacheEntryFiles(t, cache), 2)
+
+	source := strings.Replace(cacheTestSource, "Name=name", "Name=fullName", 1)
+	assert.NoError(t, os.WriteFile(fileName, []byte(source), os.ModePerm))
+	markerValues, _ = collectWithCache(t, registry, cache, fileName, source)
+	assert.Equal(t, columnMarker{Name: "fullName", Nullable: true}, markerValues.First("test:column"))
+	assert.Len(t, cacheEntryFiles(t, cache), 3)
+}
+
+type anyValueMarker struct {
+	Value any `parameter:"Value"`
+}
+
+func TestCollector_CollectShouldNotCacheValuesWhichCannotBeRestored(t *testing.T) {
+	cache, err := NewCollectionCache(t.TempDir())
+	assert.NoError(t, err)
+
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register("test:any", "github.com/procyon-projects/test", FieldLevel, &anyValueMarker{}))
+
+	source := `
+// +import=test, Pkg=github.com/procyon-projects/test
+
+package source
+
+type User struct {
+	// +test:any:Value=3
+	Name string
+}
+`
+	fileName := writeCacheTestSource(t, source)
+	markerValues, _ := collectWithCache(t, registry, cache, fileName, source)
+	assert.Equal(t, anyValueMarker{Value: 3}, markerValues.First("test:any"))
+	assert.Empty(t, cacheEntryFiles(t, cache))
+}
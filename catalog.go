@@ -0,0 +1,218 @@
+package markers
+
+import (
+	"reflect"
+	"time"
+)
+
+// CatalogVersion is the version of the catalog format, it is changed
+// whenever the format is changed in an incompatible way.
+const CatalogVersion = "1"
+
+// Catalog is a machine-readable description of the registered markers.
+type Catalog struct {
+	Version string          `json:"version"`
+	Markers []CatalogMarker `json:"markers"`
+}
+
+// CatalogMarker describes a marker definition.
+type CatalogMarker struct {
+	Name               string            `json:"name"`
+	Package            string            `json:"package"`
+	Description        string            `json:"description,omitempty"`
+	Targets            []string          `json:"targets"`
+	Repeatable         bool              `json:"repeatable"`
+	SyntaxFree         bool              `json:"syntaxFree"`
+	Deprecated         bool              `json:"deprecated,omitempty"`
+	DeprecationMessage string            `json:"deprecationMessage,omitempty"`
+	Arguments          []CatalogArgument `json:"arguments"`
+	Schema             *JSONSchema       `json:"schema"`
+}
+
+// CatalogArgument describes an argument of a marker or a field of a struct argument.
+type CatalogArgument struct {
+	Name               string      `json:"name"`
+	Description        string      `json:"description,omitempty"`
+	Type               CatalogType `json:"type"`
+	Required           bool        `json:"required"`
+	Default            any         `json:"default,omitempty"`
+	Deprecated         bool        `json:"deprecated,omitempty"`
+	DeprecationMessage string      `json:"deprecationMessage,omitempty"`
+}
+
+// CatalogType describes the type information of an argument.
+type CatalogType struct {
+	Kind    string            `json:"kind"`
+	Pointer bool              `json:"pointer,omitempty"`
+	Item    *CatalogType      `json:"item,omitempty"`
+	Enum    map[string]any    `json:"enum,omitempty"`
+	Fields  []CatalogArgument `json:"fields,omitempty"`
+}
+
+// NewCatalog returns the catalog of the given definitions.
+func NewCatalog(definitions []*Definition) Catalog {
+	catalog := Catalog{
+		Version: CatalogVersion,
+		Markers: make([]CatalogMarker, 0, len(definitions)),
+	}
+
+	for _, definition := range definitions {
+		catalog.Markers = append(catalog.Markers, definition.Catalog())
+	}
+
+	return catalog
+}
+
+// Catalog returns the catalog of the registered markers sorted by their packages and names.
+func (registry *Registry) Catalog() Catalog {
+	return NewCatalog(registry.Definitions())
+}
+
+// Catalog returns the description of the definition.
+func (definition *Definition) Catalog() CatalogMarker {
+	return CatalogMarker{
+		Name:               definition.Name,
+		Package:            definition.Package,
+		Description:        definition.Description,
+		Targets:            definition.TargetLevel.Names(),
+		Repeatable:         definition.Repeatable,
+		SyntaxFree:         definition.Output.SyntaxFree,
+		Deprecated:         definition.Deprecated,
+		DeprecationMessage: definition.DeprecationMessage,
+		Arguments:          catalogArguments(definition.arguments()),
+		Schema:             definition.JSONSchema(),
+	}
+}
+
+// arguments returns the arguments of the definition. The type of the anonymous
+// outputs is described by the Value argument.
+func (definition *Definition) arguments() map[string]Argument {
+	if definition.Output.IsAnonymous {
+		return map[string]Argument{
+			ValueArgument: {
+				Name:     ValueArgument,
+				TypeInfo: definition.Output.AnonymousTypeInfo,
+			},
+		}
+	}
+
+	return definition.Output.Fields
+}
+
+func catalogArguments(arguments map[string]Argument) []CatalogArgument {
+	catalogArguments := make([]CatalogArgument, 0, len(arguments))
+
+	for _, name := range sortedArgumentNames(arguments) {
+		argument := arguments[name]
+		catalogArguments = append(catalogArguments, CatalogArgument{
+			Name:               argument.Name,
+			Description:        argument.Description,
+			Type:               catalogType(argument.TypeInfo),
+			Required:           argument.Required,
+			Default:            catalogValue(argument.Default),
+			Deprecated:         argument.Deprecated,
+			DeprecationMessage: argument.DeprecationMessage,
+		})
+	}
+
+	return catalogArguments
+}
+
+func catalogType(typeInfo ArgumentTypeInfo) CatalogType {
+	typ := CatalogType{
+		Kind:    typeInfo.ActualType.String(),
+		Pointer: typeInfo.IsPointer,
+	}
+
+	if len(typeInfo.Enum) != 0 {
+		typ.Enum = make(map[string]any, len(typeInfo.Enum))
+
+		for key, value := range typeInfo.Enum {
+			typ.Enum[key] = catalogValue(value)
+		}
+	}
+
+	if typeInfo.ItemType != nil {
+		itemType := catalogType(*typeInfo.ItemType)
+		typ.Item = &itemType
+	}
+
+	if typeInfo.ActualType == StructType {
+		typ.Fields = catalogArguments(typeInfo.Fields)
+	}
+
+	return typ
+}
+
+// catalogValue returns the given value in the form it is written in the marker comments
+// so that it can be encoded into JSON, such as 1m30s for the durations.
+func catalogValue(value any) any {
+	if value == nil {
+		return nil
+	}
+
+	switch typedValue := value.(type) {
+	case time.Duration:
+		return typedValue.String()
+	case time.Time:
+		return typedValue.Format(time.RFC3339)
+	case TypeReference:
+		return typedValue.String()
+	case FuncReference:
+		return typedValue.String()
+	}
+
+	reflectValue := reflect.ValueOf(value)
+
+	switch reflectValue.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if reflectValue.IsNil() {
+			return nil
+		}
+
+		return catalogValue(reflectValue.Elem().Interface())
+	case reflect.Slice:
+		if reflectValue.Type() == rawType {
+			return string(reflectValue.Bytes())
+		}
+
+		items := make([]any, 0, reflectValue.Len())
+
+		for index := 0; index < reflectValue.Len(); index++ {
+			items = append(items, catalogValue(reflectValue.Index(index).Interface()))
+		}
+
+		return items
+	case reflect.Map:
+		items := make(map[string]any, reflectValue.Len())
+		iterator := reflectValue.MapRange()
+
+		for iterator.Next() {
+			items[iterator.Key().String()] = catalogValue(iterator.Value().Interface())
+		}
+
+		return items
+	case reflect.Struct:
+		fields := make(map[string]any)
+
+		for index := 0; index < reflectValue.NumField(); index++ {
+			field := reflectValue.Type().Field(index)
+
+			if field.PkgPath != "" {
+				continue
+			}
+
+			argumentName := UpperCamelCase(field.Name)
+
+			if parameterTag, ok := field.Tag.Lookup("parameter"); ok && parameterTag != "" {
+				argumentName = parameterTag
+			}
+
+			fields[argumentName] = catalogValue(reflectValue.Field(index).Interface())
+		}
+
+		return fields
+	}
+
+	return value
+}
@@ -0,0 +1,109 @@
+package markers
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegistry_Catalog(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.RegisterPackage(newTestPackage(t, definitionSource)))
+
+	catalog := registry.Catalog()
+	assert.Equal(t, CatalogVersion, catalog.Version)
+
+	names := make([]string, 0)
+
+	for _, catalogMarker := range catalog.Markers {
+		names = append(names, catalogMarker.Package+" "+catalogMarker.Name)
+	}
+
+	assert.Equal(t, []string{
+		" deprecated",
+		" import",
+		" marker",
+		" marker:enum",
+		" marker:parameter",
+		" override",
+		"github.com/procyon-projects/marker/test/source cache",
+	}, names)
+
+	cache := catalog.Markers[6]
+	assert.Equal(t, "caches the results", cache.Description)
+	assert.Equal(t, []string{"FUNCTION_LEVEL", "STRUCT_METHOD_LEVEL"}, cache.Targets)
+	assert.True(t, cache.Repeatable)
+	assert.True(t, cache.Deprecated)
+	assert.Equal(t, "use memo instead", cache.DeprecationMessage)
+	assert.Equal(t, "marker:github.com/procyon-projects/marker/test/source/cache", cache.Schema.ID)
+
+	assert.Equal(t, []CatalogArgument{
+		{
+			Name: "Index",
+			Type: CatalogType{
+				Kind: "StructType",
+				Fields: []CatalogArgument{
+					{Name: "Name", Type: CatalogType{Kind: "StringType"}, Required: true},
+					{Name: "Size", Type: CatalogType{Kind: "SignedIntegerType", Pointer: true}},
+				},
+			},
+		},
+		{
+			Name:        "Order",
+			Description: "order of the entries",
+			Type:        CatalogType{Kind: "StringType", Enum: map[string]any{"asc": "ASC", "desc": "DESC"}},
+			Required:    true,
+		},
+		{
+			Name:        "Tags",
+			Description: "tags of the entries",
+			Type:        CatalogType{Kind: "SliceType", Item: &CatalogType{Kind: "StringType"}},
+			Deprecated:  true,
+		},
+		{
+			Name:        "ttl",
+			Description: "time to live",
+			Type:        CatalogType{Kind: "DurationType"},
+			Default:     "1m0s",
+		},
+	}, cache.Arguments)
+}
+
+func TestDefinition_CatalogShouldDescribeAnonymousOutputs(t *testing.T) {
+	definition, err := MakeDefinition("test:names", "anyPkg", FieldLevel, []string{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []CatalogArgument{
+		{Name: "Value", Type: CatalogType{Kind: "SliceType", Item: &CatalogType{Kind: "StringType"}}},
+	}, definition.Catalog().Arguments)
+}
+
+func TestCatalog_MarshalJSON(t *testing.T) {
+	definition, err := MakeDynamicDefinition("test:cache", "anyPkg", FunctionLevel,
+		Argument{Name: "Tags", TypeInfo: ArgumentTypeInfo{ActualType: SliceType, ItemType: &ArgumentTypeInfo{ActualType: StringType}}, Default: []string{"a"}},
+	)
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(definition.Catalog())
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "test:cache",
+		"package": "anyPkg",
+		"targets": ["FUNCTION_LEVEL"],
+		"repeatable": false,
+		"syntaxFree": false,
+		"arguments": [
+			{"name": "Tags", "type": {"kind": "SliceType", "item": {"kind": "StringType"}}, "required": false, "default": ["a"]}
+		],
+		"schema": {
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"$id": "marker:anyPkg/test:cache",
+			"title": "test:cache",
+			"type": "object",
+			"properties": {
+				"Tags": {"type": "array", "items": {"type": "string"}, "default": ["a"]}
+			},
+			"additionalProperties": false
+		}
+	}`, string(data))
+}
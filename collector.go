@@ -6,33 +6,101 @@ import (
 	"github.com/procyon-projects/marker/packages"
 	"go/ast"
 	"go/token"
+	"sort"
 	"strings"
 )
 
 type Collector struct {
 	*Registry
+	WarningCallback WarningCallback
+	// Cache is used to skip walking and parsing the marker comments of the files which are not changed.
+	Cache *CollectionCache
 }
 
 func NewCollector(registry *Registry) *Collector {
 	return &Collector{
-		registry,
+		Registry: registry,
 	}
 }
 
 func (collector *Collector) Collect(pkg *packages.Package) (map[ast.Node]MarkerValues, error) {
+	nodeMarkers, err := collector.collect(pkg)
 
-	if pkg == nil {
-		return nil, errors.New("pkg(package) cannot be nil")
+	if err != nil {
+		return nil, err
 	}
 
-	nodeMarkers := collector.collectPackageMarkerComments(pkg)
-	markers, err := collector.parseMarkerComments(pkg, nodeMarkers)
+	return toNodeMarkerValues(nodeMarkers), nil
+}
+
+// CollectPartial collects the markers of the package as Collect does, but it also returns the markers
+// which are collected without any error when the package contains the markers which cannot be parsed.
+func (collector *Collector) CollectPartial(pkg *packages.Package) (map[ast.Node]MarkerValues, error) {
+	nodeMarkers, err := collector.collect(pkg)
+	return toNodeMarkerValues(nodeMarkers), err
+}
+
+func toNodeMarkerValues(nodeMarkers map[ast.Node][]Marker) map[ast.Node]MarkerValues {
+	markers := make(map[ast.Node]MarkerValues, len(nodeMarkers))
+
+	for node, nodeMarkerList := range nodeMarkers {
+		markers[node] = NodeMarkers{Node: node, Markers: nodeMarkerList}.Values()
+	}
+
+	return markers
+}
+
+// CollectOrdered collects the markers of the package as Collect does, but returns the nodes sorted
+// by their file names and positions, and the markers of each node in the order they are declared.
+func (collector *Collector) CollectOrdered(pkg *packages.Package) ([]NodeMarkers, error) {
+	nodeMarkers, err := collector.collect(pkg)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return markers, nil
+	orderedNodeMarkers := make([]NodeMarkers, 0, len(nodeMarkers))
+
+	for node, nodeMarkerList := range nodeMarkers {
+		orderedNodeMarkers = append(orderedNodeMarkers, NodeMarkers{Node: node, Markers: nodeMarkerList})
+	}
+
+	sort.Slice(orderedNodeMarkers, func(i, j int) bool {
+		return nodeBefore(pkg.Fset, orderedNodeMarkers[i].Node, orderedNodeMarkers[j].Node)
+	})
+
+	return orderedNodeMarkers, nil
+}
+
+// nodeBefore reports whether the node x comes before the node y in the source. The enclosing
+// node comes first if the nodes start at the same position.
+func nodeBefore(fileSet *token.FileSet, x, y ast.Node) bool {
+	xPosition, yPosition := fileSet.Position(x.Pos()), fileSet.Position(y.Pos())
+
+	if xPosition.Filename != yPosition.Filename {
+		return xPosition.Filename < yPosition.Filename
+	}
+
+	if xPosition.Offset != yPosition.Offset {
+		return xPosition.Offset < yPosition.Offset
+	}
+
+	return x.End() > y.End()
+}
+
+// collect returns the markers of the nodes in the order they are declared. The markers
+// collected without any error are returned along with the errors.
+func (collector *Collector) collect(pkg *packages.Package) (map[ast.Node][]Marker, error) {
+	if pkg == nil {
+		return nil, errors.New("pkg(package) cannot be nil")
+	}
+
+	if collector.Cache != nil {
+		return collector.collectWithCache(pkg)
+	}
+
+	nodeMarkers := collector.collectPackageMarkerComments(pkg)
+	return collector.parseMarkerComments(pkg, nodeMarkers, nil)
 }
 
 func (collector *Collector) collectPackageMarkerComments(pkg *packages.Package) map[ast.Node][]markerComment {
@@ -57,20 +125,16 @@ func (collector *Collector) collectFileMarkerComments(file *ast.File) map[ast.No
 	return visitor.nodeMarkers
 }
 
-func (collector *Collector) parseMarkerComments(pkg *packages.Package, nodeMarkerComments map[ast.Node][]markerComment) (map[ast.Node]MarkerValues, error) {
+// parseMarkerComments parses the marker comments of the nodes. The import markers are parsed
+// again with the others so that they are kept in the order they are declared.
+func (collector *Collector) parseMarkerComments(pkg *packages.Package, nodeMarkerComments map[ast.Node][]markerComment, record *collectionRecord) (map[ast.Node][]Marker, error) {
 	importNodeMarkers, err := collector.parseImportMarkerComments(pkg, nodeMarkerComments)
 
 	if err != nil {
 		return nil, err
 	}
 
-	nodeMarkerValues := make(map[ast.Node]MarkerValues)
-
-	if importNodeMarkers != nil {
-		for importNode, importMarker := range importNodeMarkers {
-			nodeMarkerValues[importNode] = importMarker
-		}
-	}
+	nodeMarkers := make(map[ast.Node][]Marker)
 
 	var fileImportAliases map[*token.File]AliasMap
 	fileImportAliases, err = collector.extractFileImportAliases(pkg, importNodeMarkers)
@@ -82,13 +146,14 @@ func (collector *Collector) parseMarkerComments(pkg *packages.Package, nodeMarke
 	var errs []error
 	for node, markerComments := range nodeMarkerComments {
 
-		markerValues := make(MarkerValues)
+		var markers []Marker
+		markerPositions := make(map[string]token.Position)
 		file := pkg.Fset.File(node.Pos())
 		importAliases := fileImportAliases[file]
 
 		for _, markerComment := range markerComments {
 			markerText := markerComment.Text()
-			markerName, _, _ := splitMarker(markerText)
+			markerName, anonymousName, _ := splitMarker(markerText)
 			targetLevel := FindTargetLevelFromNode(node)
 			alias := strings.SplitN(markerName, ":", 2)[0]
 
@@ -97,45 +162,121 @@ func (collector *Collector) parseMarkerComments(pkg *packages.Package, nodeMarke
 
 			if importMarker, ok := importAliases[alias]; ok {
 				markerName = strings.Replace(markerName, fmt.Sprintf("+%s", alias), fmt.Sprintf("+%s", importMarker.Value), 1)
-				definition, exists = collector.Lookup(markerName, importMarker.Pkg, targetLevel)
-			} else if _, isReservedMarker := reservedMarkerMap[markerName]; !isReservedMarker {
+				definition, exists = collector.lookupDefinition(markerName, anonymousName, importMarker.Pkg, targetLevel)
+			} else if !IsReservedMarker(markerName) && !IsReservedMarker(anonymousName) {
 				continue
 			} else {
-				definition, exists = collector.Lookup(markerName, "", targetLevel)
+				definition, exists = collector.lookupDefinition(markerName, anonymousName, "", targetLevel)
 			}
 
 			if !exists {
 				continue
 			}
 
-			value, err := definition.Parse(markerText)
+			position := pkg.Fset.Position(markerComment.Pos())
+
+			if firstPosition, declared := markerPositions[definition.Name]; declared && !definition.Repeatable {
+				err := DuplicateMarkerError{
+					Marker:   definition.Name,
+					FileName: firstPosition.Filename,
+					Position: Position{
+						Line:   firstPosition.Line,
+						Column: firstPosition.Column,
+					},
+				}
+				errs = appendError(errs, toParseError(err, markerComment, position))
+				continue
+			}
+
+			markerPositions[definition.Name] = position
+			value, usedArguments, err := definition.parse(markerText)
 
 			if err != nil {
-				position := pkg.Fset.Position(markerComment.Pos())
-				errs = append(errs, toParseError(err, markerComment, position))
+				errs = appendError(errs, toMarkerParseError(err, markerComment, pkg.Fset))
 				continue
 			}
 
-			/*if marker, ok := value.(Marker); ok {
-				err = marker.Validate()
-			}*/
+			if definition.hasReferences() {
+				value, err = resolveReferences(value, pkg.Types, pkg.TypesInfo, collector.findFile(pkg, node))
+
+				if err != nil {
+					errs = appendError(errs, toMarkerParseError(err, markerComment, pkg.Fset))
+					continue
+				}
+			}
+
+			if dynamicValue, isDynamic := value.(*DynamicValue); isDynamic {
+				dynamicValue.setPositions(markerComment, pkg.Fset)
+			}
+
+			err = validateMarker(value, ValidationContext{
+				Node:        node,
+				TargetLevel: targetLevel,
+				Package:     pkg,
+			})
 
 			if err != nil {
-				position := pkg.Fset.Position(markerComment.Pos())
-				errs = append(errs, toParseError(err, markerComment, position))
+				errs = appendError(errs, toParseError(err, markerComment, position))
 				continue
 			}
 
-			markerValues[definition.Name] = append(markerValues[definition.Name], value)
+			warnings := deprecationWarnings(definition, usedArguments, position)
+			collector.warn(warnings...)
+			record.add(node, definition, value, position, warnings)
+			markers = append(markers, Marker{
+				Name:     definition.Name,
+				Value:    value,
+				Position: position,
+			})
 		}
 
-		if len(markerValues) != 0 {
-			nodeMarkerValues[node] = markerValues
+		if len(markers) != 0 {
+			nodeMarkers[node] = markers
 		}
 
 	}
 
-	return nodeMarkerValues, NewErrorList(errs)
+	return nodeMarkers, NewErrorList(errs)
+}
+
+// lookupDefinition returns the definition of the marker. The anonymous name is looked up first
+// because the value syntax of the markers having a colon in their names such as
+// +marker:parameter=Name cannot be distinguished from the argument syntax.
+func (collector *Collector) lookupDefinition(name, anonymousName, pkg string, targetLevel TargetLevel) (*Definition, bool) {
+	if anonymousName != name {
+		if definition, exists := collector.Lookup(anonymousName, pkg, targetLevel); exists {
+			return definition, true
+		}
+	}
+
+	return collector.Lookup(name, pkg, targetLevel)
+}
+
+// findFile returns the file which contains the given node.
+func (collector *Collector) findFile(pkg *packages.Package, node ast.Node) *ast.File {
+	if file, isFile := node.(*ast.File); isFile {
+		return file
+	}
+
+	tokenFile := pkg.Fset.File(node.Pos())
+
+	for _, file := range pkg.Syntax {
+		if pkg.Fset.File(file.Pos()) == tokenFile {
+			return file
+		}
+	}
+
+	return nil
+}
+
+func (collector *Collector) warn(warnings ...Warning) {
+	if collector.WarningCallback == nil {
+		return
+	}
+
+	for _, warning := range warnings {
+		collector.WarningCallback(warning)
+	}
 }
 
 func (collector *Collector) parseImportMarkerComments(pkg *packages.Package, nodeMarkerComments map[ast.Node][]markerComment) (map[ast.Node]MarkerValues, error) {
@@ -167,18 +308,19 @@ func (collector *Collector) parseImportMarkerComments(pkg *packages.Package, nod
 			value, err := definition.Parse(markerText)
 
 			if err != nil {
-				position := pkg.Fset.Position(markerComment.Pos())
-				errs = append(errs, toParseError(err, markerComment, position))
+				errs = appendError(errs, toMarkerParseError(err, markerComment, pkg.Fset))
 				continue
 			}
 
-			/*if marker, ok := value.(Marker); ok {
-				err = marker.Validate()
-			}*/
+			err = validateMarker(value, ValidationContext{
+				Node:        node,
+				TargetLevel: PackageLevel,
+				Package:     pkg,
+			})
 
 			if err != nil {
 				position := pkg.Fset.Position(markerComment.Pos())
-				errs = append(errs, toParseError(err, markerComment, position))
+				errs = appendError(errs, toParseError(err, markerComment, position))
 				continue
 			}
 
@@ -1,11 +1,67 @@
 package markers
 
 import (
+	"errors"
 	"github.com/procyon-projects/marker/packages"
 	"github.com/stretchr/testify/assert"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	gopackages "golang.org/x/tools/go/packages"
 	"testing"
 )
 
+func newTestPackage(t *testing.T, source string) *packages.Package {
+	return newTestPackageFile(t, "test.go", source)
+}
+
+func newTestPackageFile(t *testing.T, fileName, source string) *packages.Package {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fileName, source, parser.ParseComments)
+
+	if err != nil {
+		t.Fatalf("test source could not be parsed: %v", err)
+	}
+
+	info := &types.Info{
+		Defs:   make(map[*ast.Ident]types.Object),
+		Uses:   make(map[*ast.Ident]types.Object),
+		Scopes: make(map[ast.Node]*types.Scope),
+	}
+	config := &types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+	}
+	pkg, err := config.Check("github.com/procyon-projects/marker/test/source", fset, []*ast.File{file}, info)
+
+	if err != nil {
+		t.Fatalf("test source could not be type-checked: %v", err)
+	}
+
+	return &packages.Package{
+		Package: &gopackages.Package{
+			ID:        "github.com/procyon-projects/marker/test/source",
+			Name:      file.Name.Name,
+			PkgPath:   "github.com/procyon-projects/marker/test/source",
+			Fset:      fset,
+			Syntax:    []*ast.File{file},
+			Types:     pkg,
+			TypesInfo: info,
+		},
+	}
+}
+
+func findMarkerValues(nodes map[ast.Node]MarkerValues, name string) MarkerValues {
+	for node, markerValues := range nodes {
+		if (ValidationContext{Node: node}).Name() == name {
+			return markerValues
+		}
+	}
+
+	return nil
+}
+
 func TestCollector_Collect(t *testing.T) {
 	result, _ := packages.LoadPackages("github.com/procyon-projects/marker/test/...")
 	pkg, _ := result.Lookup("github.com/procyon-projects/marker/test/menu")
@@ -23,3 +79,465 @@ func TestCollector_Collect(t *testing.T) {
 	assert.NotNil(t, nodes)
 	assert.NoError(t, err)
 }
+
+type validatedMarker struct {
+	Value string `parameter:"Value"`
+}
+
+func (m validatedMarker) Validate() error {
+	if m.Value == "invalid" {
+		return errors.New("value cannot be 'invalid'")
+	}
+
+	return nil
+}
+
+type pointerValidatedMarker struct {
+	Value int `parameter:"Value"`
+}
+
+func (m *pointerValidatedMarker) Validate() error {
+	if m.Value < 0 {
+		return errors.New("value cannot be negative")
+	}
+
+	return nil
+}
+
+type exportedFieldMarker struct {
+	Value string `parameter:"Value"`
+}
+
+func (m exportedFieldMarker) ValidateWithContext(ctx ValidationContext) error {
+	if ctx.TargetLevel != FieldLevel || !ctx.IsExported() {
+		return errors.New("marker can only be used on exported fields")
+	}
+
+	return nil
+}
+
+const validationTestSource = `
+// +import=test, Pkg=github.com/procyon-projects/test
+
+package source
+
+type Entity struct {
+	// +test:validated:Value=valid
+	// +test:pointer-validated:Value=3
+	// +test:exported-field:Value=any
+	Name string
+	// +test:validated:Value=invalid
+	// +test:pointer-validated:Value=-2
+	// +test:exported-field:Value=any
+	surname string
+}
+`
+
+func newValidationTestRegistry(t *testing.T) *Registry {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register("test:validated", "github.com/procyon-projects/test", FieldLevel, &validatedMarker{}))
+	assert.NoError(t, registry.Register("test:pointer-validated", "github.com/procyon-projects/test", FieldLevel, &pointerValidatedMarker{}))
+	assert.NoError(t, registry.Register("test:exported-field", "github.com/procyon-projects/test", FieldLevel, &exportedFieldMarker{}))
+	return registry
+}
+
+func TestCollector_CollectShouldValidateMarkers(t *testing.T) {
+	collector := NewCollector(newValidationTestRegistry(t))
+	nodes, err := collector.Collect(newTestPackage(t, validationTestSource))
+
+	assert.Nil(t, nodes)
+	assert.Error(t, err)
+
+	errorList, isErrorList := err.(ErrorList)
+	assert.True(t, isErrorList)
+	assert.Len(t, errorList, 3)
+
+	expectedErrors := map[int]string{
+		11: "value cannot be 'invalid'",
+		12: "value cannot be negative",
+		13: "marker can only be used on exported fields",
+	}
+
+	for _, err = range errorList {
+		parserError, isParserError := err.(ParserError)
+		assert.True(t, isParserError)
+		assert.Equal(t, "test.go", parserError.FileName)
+		assert.Equal(t, 2, parserError.Position.Column)
+		assert.Equal(t, expectedErrors[parserError.Position.Line], parserError.Error())
+	}
+}
+
+func TestCollector_CollectPartial(t *testing.T) {
+	collector := NewCollector(newValidationTestRegistry(t))
+	nodes, err := collector.CollectPartial(newTestPackage(t, validationTestSource))
+
+	assert.Error(t, err)
+	assert.Len(t, err.(ErrorList), 3)
+	assert.Equal(t, MarkerValues{
+		"test:validated":         {validatedMarker{Value: "valid"}},
+		"test:pointer-validated": {pointerValidatedMarker{Value: 3}},
+		"test:exported-field":    {exportedFieldMarker{Value: "any"}},
+	}, findMarkerValues(nodes, "Name"))
+	assert.Nil(t, findMarkerValues(nodes, "surname"))
+}
+
+func TestCollector_CollectShouldReturnValidatedMarkers(t *testing.T) {
+	collector := NewCollector(newValidationTestRegistry(t))
+	nodes, err := collector.Collect(newTestPackage(t, `
+// +import=test, Pkg=github.com/procyon-projects/test
+
+package source
+
+type Entity struct {
+	// +test:validated:Value=valid
+	// +test:pointer-validated:Value=3
+	// +test:exported-field:Value=any
+	Name string
+}
+`))
+
+	assert.NoError(t, err)
+
+	markerValues := findMarkerValues(nodes, "Name")
+	assert.Equal(t, validatedMarker{Value: "valid"}, markerValues.First("test:validated"))
+	assert.Equal(t, pointerValidatedMarker{Value: 3}, markerValues.First("test:pointer-validated"))
+	assert.Equal(t, exportedFieldMarker{Value: "any"}, markerValues.First("test:exported-field"))
+}
+
+func TestCollector_CollectShouldValidateImportMarkers(t *testing.T) {
+	collector := NewCollector(NewRegistry())
+	_, err := collector.Collect(newTestPackage(t, `
+// +import=" ", Pkg=github.com/procyon-projects/test
+
+package source
+`))
+
+	assert.Error(t, err)
+
+	errorList, isErrorList := err.(ErrorList)
+	assert.True(t, isErrorList)
+	assert.Len(t, errorList, 1)
+
+	parserError, isParserError := errorList[0].(ParserError)
+	assert.True(t, isParserError)
+	assert.Equal(t, Position{Line: 2, Column: 1}, parserError.Position)
+	assert.Equal(t, "'Value' argument cannot be nil or empty", parserError.Error())
+}
+
+type tableMarker struct {
+	Value string `parameter:"Value"`
+}
+
+type indexMarker struct {
+	Value string `parameter:"Value"`
+}
+
+func TestCollector_CollectShouldReportDuplicateNonRepeatableMarkers(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register("test:table", "github.com/procyon-projects/test", StructTypeLevel, &tableMarker{}))
+
+	indexDefinition, _ := MakeDefinition("test:index", "github.com/procyon-projects/test", StructTypeLevel, &indexMarker{})
+	indexDefinition.Repeatable = true
+	assert.NoError(t, registry.RegisterWithDefinition(indexDefinition))
+
+	collector := NewCollector(registry)
+	_, err := collector.Collect(newTestPackage(t, `
+// +import=test, Pkg=github.com/procyon-projects/test
+
+package source
+
+// +test:table:Value=users
+// +test:index:Value=name
+// +test:index:Value=surname
+// +test:table:Value=people
+type User struct {
+}
+`))
+
+	assert.Error(t, err)
+
+	errorList, isErrorList := err.(ErrorList)
+	assert.True(t, isErrorList)
+	assert.Len(t, errorList, 1)
+
+	parserError, isParserError := errorList[0].(ParserError)
+	assert.True(t, isParserError)
+	assert.Equal(t, Position{Line: 9, Column: 1}, parserError.Position)
+	assert.Equal(t, DuplicateMarkerError{
+		Marker:   "test:table",
+		FileName: "test.go",
+		Position: Position{Line: 6, Column: 1},
+	}, parserError.error)
+}
+
+func TestCollector_CollectShouldAllowRepeatableMarkers(t *testing.T) {
+	registry := NewRegistry()
+	indexDefinition, _ := MakeDefinition("test:index", "github.com/procyon-projects/test", StructTypeLevel, &indexMarker{})
+	indexDefinition.Repeatable = true
+	assert.NoError(t, registry.RegisterWithDefinition(indexDefinition))
+
+	collector := NewCollector(registry)
+	nodes, err := collector.Collect(newTestPackage(t, `
+// +import=test, Pkg=github.com/procyon-projects/test
+
+package source
+
+// +test:index:Value=name
+// +test:index:Value=surname
+type User struct {
+}
+`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []any{indexMarker{Value: "name"}, indexMarker{Value: "surname"}}, findMarkerValues(nodes, "User").AllMarkers("test:index"))
+}
+
+func TestCollector_CollectOrdered(t *testing.T) {
+	registry := NewRegistry()
+	indexDefinition, _ := MakeDefinition("test:index", "github.com/procyon-projects/test", StructTypeLevel|FieldLevel, &indexMarker{})
+	indexDefinition.Repeatable = true
+	assert.NoError(t, registry.RegisterWithDefinition(indexDefinition))
+	assert.NoError(t, registry.Register("test:column", "github.com/procyon-projects/test", FieldLevel, &columnMarker{}))
+
+	collector := NewCollector(registry)
+	nodeMarkers, err := collector.CollectOrdered(newTestPackage(t, `
+// +import=test, Pkg=github.com/procyon-projects/test
+
+package source
+
+// +test:index:Value=name
+type User struct {
+	// +test:index:Value=surname
+	// +test:column:Name=surname
+	// +test:index:Value=fullName
+	Surname string
+	// +test:column:Name=name
+	Name string
+}
+
+// +test:index:Value=id
+type Account struct {
+}
+`))
+
+	assert.NoError(t, err)
+
+	var names []string
+	for _, nodeMarker := range nodeMarkers {
+		names = append(names, (ValidationContext{Node: nodeMarker.Node}).Name())
+	}
+
+	assert.Equal(t, []string{"User", "Surname", "Name", "Account"}, names)
+	assert.Equal(t, []Marker{
+		{Name: "test:index", Value: indexMarker{Value: "surname"}, Position: token.Position{Filename: "test.go", Offset: 118, Line: 8, Column: 2}},
+		{Name: "test:column", Value: columnMarker{Name: "surname"}, Position: token.Position{Filename: "test.go", Offset: 148, Line: 9, Column: 2}},
+		{Name: "test:index", Value: indexMarker{Value: "fullName"}, Position: token.Position{Filename: "test.go", Offset: 178, Line: 10, Column: 2}},
+	}, nodeMarkers[1].Markers)
+	assert.Equal(t, MarkerValues{
+		"test:index":  {indexMarker{Value: "surname"}, indexMarker{Value: "fullName"}},
+		"test:column": {columnMarker{Name: "surname"}},
+	}, nodeMarkers[1].Values())
+}
+
+type columnMarker struct {
+	Name     string `parameter:"Name"`
+	Nullable bool   `parameter:"Nullable" deprecated:"use Optional instead"`
+	Length   int    `parameter:"Length" deprecated:""`
+}
+
+func TestCollector_CollectShouldReportDeprecationWarnings(t *testing.T) {
+	registry := NewRegistry()
+	columnDefinition, _ := MakeDefinition("test:column", "github.com/procyon-projects/test", FieldLevel, &columnMarker{})
+	columnDefinition.Deprecated = true
+	columnDefinition.DeprecationMessage = "use test:field instead"
+	assert.NoError(t, registry.RegisterWithDefinition(columnDefinition))
+
+	var warnings []Warning
+	collector := NewCollector(registry)
+	collector.WarningCallback = func(warning Warning) {
+		warnings = append(warnings, warning)
+	}
+
+	nodes, err := collector.Collect(newTestPackage(t, `
+// +import=test, Pkg=github.com/procyon-projects/test
+
+package source
+
+type User struct {
+	// +test:column:Name=name, Nullable=true, Length=3
+	Name string
+}
+`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, columnMarker{Name: "name", Nullable: true, Length: 3}, findMarkerValues(nodes, "Name").First("test:column"))
+	assert.Equal(t, []Warning{
+		{
+			FileName: "test.go",
+			Position: Position{Line: 7, Column: 2},
+			Message:  "the marker 'test:column' is deprecated: use test:field instead",
+		},
+		{
+			FileName: "test.go",
+			Position: Position{Line: 7, Column: 2},
+			Message:  "the argument 'Length' of the marker 'test:column' is deprecated",
+		},
+		{
+			FileName: "test.go",
+			Position: Position{Line: 7, Column: 2},
+			Message:  "the argument 'Nullable' of the marker 'test:column' is deprecated: use Optional instead",
+		},
+	}, warnings)
+}
+
+func TestCollector_CollectShouldReportUnknownArgumentPositions(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register("test:column", "github.com/procyon-projects/test", FieldLevel, &columnMarker{}))
+
+	collector := NewCollector(registry)
+	_, err := collector.Collect(newTestPackage(t, `
+// +import=test, Pkg=github.com/procyon-projects/test
+
+package source
+
+type User struct {
+	// +test:column:Nmae=name
+	Name string
+	//   +test:column:Name=name, \
+	//     Lenght=3
+	Surname string
+}
+`))
+
+	assert.ElementsMatch(t, ErrorList{
+		ParserError{
+			FileName: "test.go",
+			Position: Position{Line: 7, Column: 18},
+			error:    UnknownArgumentError{Marker: "test:column", Argument: "Nmae", Suggestion: "Name", Offset: 13},
+		},
+		ParserError{
+			FileName: "test.go",
+			Position: Position{Line: 10, Column: 9},
+			error:    UnknownArgumentError{Marker: "test:column", Argument: "Lenght", Suggestion: "Length", Offset: 24},
+		},
+	}, err)
+}
+
+func TestCollector_CollectShouldReportScannerErrorPositions(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register("test:column", "github.com/procyon-projects/test", FieldLevel, &columnMarker{}))
+
+	collector := NewCollector(registry)
+	_, err := collector.Collect(newTestPackage(t, `
+// +import=test, Pkg=github.com/procyon-projects/test
+
+package source
+
+type User struct {
+	// +test:column:Name=name, \
+	//   Length=abc
+	Name string
+}
+`))
+
+	assert.Equal(t, ErrorList{
+		ParserError{
+			FileName: "test.go",
+			Position: Position{Line: 8, Column: 14},
+			error:    ScannerError{Message: "got \"abc\"; want Integer", Offset: 31},
+		},
+	}, err)
+}
+
+type provideMarker struct {
+	Value  FuncReference   `parameter:"Value"`
+	Type   *TypeReference  `parameter:"Type"`
+	Codecs []TypeReference `parameter:"Codecs"`
+}
+
+func TestCollector_CollectShouldResolveReferences(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register("test:provide", "github.com/procyon-projects/test", FunctionLevel, &provideMarker{}))
+
+	collector := NewCollector(registry)
+	nodes, err := collector.Collect(newTestPackage(t, `
+// +import=test, Pkg=github.com/procyon-projects/test
+
+package source
+
+import (
+	clock "time"
+)
+
+type Service struct {
+	CreatedAt clock.Time
+}
+
+// +test:provide:Value=NewService, Type=Service, Codecs={clock.Time, error}
+func NewService() *Service {
+	return &Service{}
+}
+`))
+
+	assert.NoError(t, err)
+	marker := findMarkerValues(nodes, "NewService").First("test:provide").(provideMarker)
+
+	assert.Equal(t, "NewService", marker.Value.Name)
+	assert.Equal(t, "github.com/procyon-projects/marker/test/source", marker.Value.PkgPath)
+	assert.IsType(t, &types.Func{}, marker.Value.Object)
+
+	assert.Equal(t, "Service", marker.Type.String())
+	assert.Equal(t, "github.com/procyon-projects/marker/test/source", marker.Type.PkgPath)
+	assert.IsType(t, &types.TypeName{}, marker.Type.Object)
+
+	assert.Len(t, marker.Codecs, 2)
+	assert.Equal(t, "clock.Time", marker.Codecs[0].String())
+	assert.Equal(t, "time", marker.Codecs[0].PkgPath)
+	assert.Equal(t, "time.Time", marker.Codecs[0].Object.Type().String())
+	assert.Equal(t, "error", marker.Codecs[1].Name)
+	assert.Equal(t, "", marker.Codecs[1].PkgPath)
+	assert.NotNil(t, marker.Codecs[1].Object)
+}
+
+func TestCollector_CollectShouldReportUnresolvedReferences(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register("test:provide", "github.com/procyon-projects/test", FunctionLevel, &provideMarker{}))
+
+	collector := NewCollector(registry)
+	_, err := collector.Collect(newTestPackage(t, `
+// +import=test, Pkg=github.com/procyon-projects/test
+
+package source
+
+type Service struct {
+}
+
+// +test:provide:Value=Service, Type=NewService, Codecs={time.Time, Missing}
+func NewService() *Service {
+	return &Service{}
+}
+`))
+
+	assert.ElementsMatch(t, ErrorList{
+		ParserError{
+			FileName: "test.go",
+			Position: Position{Line: 9, Column: 24},
+			error:    ReferenceError{Reference: "Service", Reason: "'Service' is not a function", Offset: 20},
+		},
+		ParserError{
+			FileName: "test.go",
+			Position: Position{Line: 9, Column: 38},
+			error:    ReferenceError{Reference: "NewService", Reason: "'NewService' is not a type", Offset: 34},
+		},
+		ParserError{
+			FileName: "test.go",
+			Position: Position{Line: 9, Column: 58},
+			error:    ReferenceError{Reference: "time.Time", Reason: "package 'time' is not imported", Offset: 54},
+		},
+		ParserError{
+			FileName: "test.go",
+			Position: Position{Line: 9, Column: 69},
+			error:    ReferenceError{Reference: "Missing", Reason: "'Missing' is not declared", Offset: 65},
+		},
+	}, err)
+}
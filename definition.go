@@ -3,6 +3,7 @@ package markers
 import (
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"strings"
 )
@@ -13,8 +14,11 @@ const (
 )
 
 type Output struct {
-	Type              reflect.Type
-	IsAnonymous       bool
+	Type        reflect.Type
+	IsAnonymous bool
+	// IsDynamic reports whether the output does not have a Go type,
+	// the values are parsed into *DynamicValue.
+	IsDynamic         bool
 	SyntaxFree        bool
 	AnonymousTypeInfo ArgumentTypeInfo
 	Fields            map[string]Argument
@@ -22,12 +26,17 @@ type Output struct {
 }
 
 type Definition struct {
-	Name        string
-	Package     string
-	TargetLevel TargetLevel
-	Repeatable  bool
-	Deprecated  bool
-	Output      Output
+	Name               string
+	Description        string
+	Package            string
+	TargetLevel        TargetLevel
+	Repeatable         bool
+	Deprecated         bool
+	DeprecationMessage string
+	// AllowUnknownArguments makes the definition ignore the arguments
+	// which do not exist in the output instead of reporting them.
+	AllowUnknownArguments bool
+	Output                Output
 }
 
 func MakeDefinition(name, pkg string, level TargetLevel, output any) (*Definition, error) {
@@ -135,34 +144,74 @@ func (definition *Definition) extract() error {
 	return nil
 }
 
-// TODO fix parse method implementation
+// Parse parses the given marker comment and returns the output value. The arguments which are
+// not present in the comment are set to their default values.
 func (definition *Definition) Parse(comment string) (interface{}, error) {
+	value, _, err := definition.parse(comment)
+	return value, err
+}
+
+// parse parses the given marker comment and returns the output value
+// with the names of the arguments which are present in the comment.
+func (definition *Definition) parse(comment string) (interface{}, map[string]struct{}, error) {
 	if definition.Output.SyntaxFree {
-		return definition.parseSyntaxFree(comment), nil
+		return definition.parseSyntaxFree(comment), nil, nil
 	}
 
-	output := reflect.Indirect(reflect.New(definition.Output.Type))
+	var output reflect.Value
+	var dynamicOutput *DynamicValue
+
+	if definition.Output.IsDynamic {
+		dynamicOutput = newDynamicValue()
+	} else {
+		output = reflect.Indirect(reflect.New(definition.Output.Type))
+	}
+
+	// the arguments are scanned within the comment so that the positions
+	// of the tokens are the offsets within the marker text.
+	source := comment
+	fieldsOffset := len(comment) - len(strings.TrimLeft(comment, " \t"))
 	comment = strings.TrimLeft(comment, " \t")
-	_, _, fields := splitMarker(comment)
 
 	isValueSyntax := true
 	if strings.HasPrefix(comment, "+"+definition.Name+":") {
 		isValueSyntax = false
+		fieldsOffset += len(definition.Name) + 2
+	} else if strings.HasPrefix(comment, "+"+definition.Name) {
+		fieldsOffset += len(definition.Name) + 1
 	} else {
+		_, _, fields := splitMarker(comment)
 		tempComment := strings.Replace(comment, fields, "", -1)
 		tempComment = strings.Replace(tempComment, "+"+definition.Name, "", -1)
-		fields = tempComment + fields
+		source = tempComment + fields
+		fieldsOffset = 0
 	}
 
 	var errs []error
-	scanner := NewScanner(fields)
+	scanner := NewScanner(source)
+	scanner.SetSearchIndex(fieldsOffset)
 	scanner.ErrorCallback = func(scanner *Scanner, message string) {
-		errs = append(errs, ScannerError{
-			Message: message,
-		})
+		errs = append(errs, scanner.newError(message))
 	}
 	seen := make(map[string]struct{}, len(definition.Output.Fields))
 
+	// the anonymous outputs can only be written with the value syntax
+	if definition.Output.IsAnonymous {
+		if scanner.Peek() != EOF && scanner.Expect('=', "Equal sign") {
+			seen[ValueArgument] = struct{}{}
+			errorCount := scanner.ErrorCount()
+			err := definition.Output.AnonymousTypeInfo.Parse(scanner, output)
+
+			if err != nil && scanner.ErrorCount() == errorCount {
+				scanner.AddError(err.Error())
+			} else if err == nil && scanner.Scan() != EOF {
+				scanner.AddError(fmt.Sprintf("got %q; want EOF", scanner.Token()))
+			}
+		}
+
+		return output.Interface(), seen, NewErrorList(errs)
+	}
+
 	if len(definition.Output.Fields) != 0 && scanner.Peek() != EOF {
 		for {
 			var argument Argument
@@ -170,40 +219,28 @@ func (definition *Definition) Parse(comment string) (interface{}, error) {
 			argumentExists := false
 			fieldName := ""
 			fieldExists := false
-			scanner.SkipWhitespaces()
-
-			if !scanner.Expect(Identifier, "Value or Argument value") {
-				if isValueSyntax {
-					if "=" != scanner.Token() && len(seen) == 0 {
-						break
-					} else if "," == scanner.Token() {
-						continue
-					} else {
-						argumentName = "Value"
-						fieldName, fieldExists = definition.Output.FieldNames[argumentName]
-						argument, argumentExists = definition.Output.Fields[argumentName]
-						if !fieldExists || !argumentExists {
-							var anyValue interface{}
-							(&ArgumentTypeInfo{ActualType: AnyType}).Parse(scanner, reflect.ValueOf(&anyValue))
-							//goto nextAttribute
-						}
-					}
-				} else {
-					if "," == scanner.Token() {
-						continue
-					}
+			argumentOffset := 0
+			token := scanner.Scan()
 
+			if token == EOF {
+				break
+			} else if token == ',' {
+				continue
+			} else if token != Identifier {
+				if !isValueSyntax || token != '=' || len(seen) != 0 {
+					scanner.AddError(fmt.Sprintf("got %q; want Argument name", scanner.Token()))
 					break
 				}
+
+				argumentName = "Value"
+				argumentOffset = scanner.TokenPosition() + 1
+				fieldName, fieldExists = definition.Output.FieldNames[argumentName]
+				argument, argumentExists = definition.Output.Fields[argumentName]
 			} else {
 				argumentName = scanner.Token()
+				argumentOffset = scanner.TokenPosition()
 				fieldName, fieldExists = definition.Output.FieldNames[argumentName]
 				argument, argumentExists = definition.Output.Fields[argumentName]
-				if !fieldExists || !argumentExists {
-					var anyValue interface{}
-					(&ArgumentTypeInfo{ActualType: AnyType}).Parse(scanner, reflect.ValueOf(&anyValue))
-					//goto nextAttribute
-				}
 
 				scanner.SkipWhitespaces()
 
@@ -212,21 +249,49 @@ func (definition *Definition) Parse(comment string) (interface{}, error) {
 				}
 			}
 
-			seen[argumentName] = struct{}{}
+			if !argumentExists || (!fieldExists && !definition.Output.IsDynamic) {
+				if !definition.AllowUnknownArguments {
+					errs = append(errs, definition.unknownArgumentError(argumentName, argumentOffset))
+				}
+
+				var anyValue interface{}
+				(&ArgumentTypeInfo{ActualType: AnyType}).Parse(scanner, reflect.ValueOf(&anyValue))
+			} else {
+				seen[argumentName] = struct{}{}
 
-			fieldValue := output.FieldByName(fieldName)
+				var fieldValue reflect.Value
 
-			if !fieldValue.CanSet() {
-				break
-			}
+				if definition.Output.IsDynamic {
+					fieldType, _ := argument.TypeInfo.dynamicType()
+					fieldValue = reflect.New(fieldType).Elem()
+				} else {
+					fieldValue = output.FieldByName(fieldName)
+				}
 
-			err := argument.TypeInfo.Parse(scanner, fieldValue)
+				if !fieldValue.CanSet() {
+					break
+				}
 
-			if err != nil {
-				break
+				errorCount := scanner.ErrorCount()
+				err := argument.TypeInfo.Parse(scanner, fieldValue)
+
+				if err != nil {
+					if scanner.ErrorCount() == errorCount {
+						scanner.AddError(err.Error())
+					}
+
+					break
+				}
+
+				if definition.Output.IsDynamic {
+					dynamicOutput.set(DynamicArgument{
+						Name:   argumentName,
+						Value:  fieldValue.Interface(),
+						Offset: argumentOffset,
+					})
+				}
 			}
 
-			//nextAttribute:
 			if scanner.Peek() == EOF {
 				break
 			}
@@ -236,16 +301,124 @@ func (definition *Definition) Parse(comment string) (interface{}, error) {
 		}
 	}
 
-	for argumentName, argument := range definition.Output.Fields {
-		if _, wasSeen := seen[argumentName]; !wasSeen && argument.Required {
-			scanner.AddError(fmt.Sprintf("missing argument %q", argumentName))
+	for _, argumentName := range sortedArgumentNames(definition.Output.Fields) {
+		if _, wasSeen := seen[argumentName]; wasSeen {
+			continue
+		}
+
+		argument := definition.Output.Fields[argumentName]
+
+		if argument.Default != nil && definition.Output.IsDynamic {
+			dynamicOutput.set(DynamicArgument{
+				Name:      argumentName,
+				Value:     copyValue(reflect.ValueOf(argument.Default)).Interface(),
+				IsDefault: true,
+			})
+			continue
+		}
+
+		if argument.Default != nil {
+			fieldValue := output.FieldByName(definition.Output.FieldNames[argumentName])
+
+			if fieldValue.CanSet() {
+				fieldValue.Set(copyValue(reflect.ValueOf(argument.Default)))
+			}
+
+			continue
+		}
+
+		if argument.Required {
+			errs = append(errs, ScannerError{
+				Message: fmt.Sprintf("missing argument %q", argumentName),
+			})
+		}
+	}
+
+	if definition.Output.IsDynamic {
+		return dynamicOutput, seen, NewErrorList(errs)
+	}
+
+	return output.Interface(), seen, NewErrorList(errs)
+}
+
+// hasReferences reports whether any argument of the definition
+// contains a type or function reference to be resolved.
+func (definition *Definition) hasReferences() bool {
+	if definition.Output.IsAnonymous {
+		return definition.Output.AnonymousTypeInfo.hasReferences()
+	}
+
+	for _, argument := range definition.Output.Fields {
+		if argument.TypeInfo.hasReferences() {
+			return true
 		}
 	}
 
-	return output.Interface(), NewErrorList(errs)
+	return false
+}
+
+// unknownArgumentError returns an error for the argument which does not exist in the output.
+// The closest argument name is suggested if there is any.
+func (definition *Definition) unknownArgumentError(argumentName string, offset int) error {
+	suggestion, _ := ClosestMatch(argumentName, sortedArgumentNames(definition.Output.Fields))
+
+	return UnknownArgumentError{
+		Marker:     definition.Name,
+		Argument:   argumentName,
+		Suggestion: suggestion,
+		Offset:     offset,
+	}
+}
+
+// SetDefaultValue sets the default value of the argument with the given name.
+// The string values are parsed by using the type information of the argument,
+// the others are converted into the type of the argument.
+func (definition *Definition) SetDefaultValue(argumentName string, value any) error {
+	argument, exists := definition.Output.Fields[argumentName]
+
+	if !exists {
+		return fmt.Errorf("argument '%s' does not exist", argumentName)
+	}
+
+	fieldType, err := definition.argumentType(argumentName)
+
+	if err != nil {
+		return err
+	}
+
+	if value == nil {
+		argument.Default = nil
+		definition.Output.Fields[argumentName] = argument
+		return nil
+	}
+
+	var defaultValue any
+
+	if text, isText := value.(string); isText && argument.TypeInfo.ActualType != StringType {
+		defaultValue, err = argument.TypeInfo.parseText(fieldType, text)
+	} else {
+		var convertedValue reflect.Value
+		convertedValue, err = convertValue(reflect.ValueOf(value), fieldType)
+
+		if err == nil {
+			defaultValue = convertedValue.Interface()
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("default value of argument '%s' cannot be parsed: %w", argumentName, err)
+	}
+
+	argument.Default = defaultValue
+	definition.Output.Fields[argumentName] = argument
+	return nil
 }
 
 func (definition *Definition) parseSyntaxFree(marker string) any {
+	if definition.Output.IsDynamic {
+		return definition.parseDynamicSyntaxFree(marker)
+	}
+
 	output := reflect.Indirect(reflect.New(definition.Output.Type))
 
 	fieldName, exists := definition.Output.FieldNames[ValueArgument]
@@ -267,6 +440,13 @@ func (definition *Definition) parseSyntaxFree(marker string) any {
 		return output.Interface()
 	}
 
+	text := strings.Trim(strings.Replace(marker, fmt.Sprintf("+%s", definition.Name), "", 1), " ")
+
+	if text == "" && argument.Default != nil {
+		fieldValue.Set(copyValue(reflect.ValueOf(argument.Default)))
+		return output.Interface()
+	}
+
 	fieldOutType := fieldValue.Type()
 
 	if argument.TypeInfo.IsPointer {
@@ -274,7 +454,7 @@ func (definition *Definition) parseSyntaxFree(marker string) any {
 		fieldValue = fieldValue.Elem()
 	}
 
-	value := reflect.ValueOf(strings.Trim(strings.Replace(marker, fmt.Sprintf("+%s", definition.Name), "", 1), " "))
+	value := reflect.ValueOf(text)
 
 	if fieldOutType != value.Type() {
 		value = value.Convert(fieldOutType)
@@ -284,3 +464,211 @@ func (definition *Definition) parseSyntaxFree(marker string) any {
 
 	return output.Interface()
 }
+
+// copyValue returns a copy of the given value so that the slices, maps and pointers
+// are not shared between the outputs.
+func copyValue(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return value
+		}
+
+		copied := reflect.New(value.Type().Elem())
+		copied.Elem().Set(copyValue(value.Elem()))
+		return copied
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+
+		for index := 0; index < value.Len(); index++ {
+			copied.Index(index).Set(copyValue(value.Index(index)))
+		}
+
+		return copied
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+
+		copied := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iterator := value.MapRange()
+
+		for iterator.Next() {
+			copied.SetMapIndex(iterator.Key(), copyValue(iterator.Value()))
+		}
+
+		return copied
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(copyValue(value.Elem()))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(value)
+
+		for index := 0; index < copied.NumField(); index++ {
+			if field := copied.Field(index); field.CanSet() {
+				field.Set(copyValue(field))
+			}
+		}
+
+		return copied
+	}
+
+	return value
+}
+
+// convertValue converts the given value into the given type. The items of the slices
+// and the maps are converted one by one.
+func convertValue(value reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	if value.Kind() == reflect.Interface && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Type().AssignableTo(typ) {
+		return copyValue(value), nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		elem, err := convertValue(value, typ.Elem())
+
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		pointer := reflect.New(typ.Elem())
+		pointer.Elem().Set(elem)
+		return pointer, nil
+	case reflect.Slice:
+		if value.Kind() != reflect.Slice {
+			break
+		}
+
+		converted := reflect.MakeSlice(typ, value.Len(), value.Len())
+
+		for index := 0; index < value.Len(); index++ {
+			item, err := convertValue(value.Index(index), typ.Elem())
+
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			converted.Index(index).Set(item)
+		}
+
+		return converted, nil
+	case reflect.Map:
+		if value.Kind() != reflect.Map {
+			break
+		}
+
+		converted := reflect.MakeMapWithSize(typ, value.Len())
+		iterator := value.MapRange()
+
+		for iterator.Next() {
+			key, err := convertValue(iterator.Key(), typ.Key())
+
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			var item reflect.Value
+			item, err = convertValue(iterator.Value(), typ.Elem())
+
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			converted.SetMapIndex(key, item)
+		}
+
+		return converted, nil
+	case reflect.String, reflect.Bool:
+		if value.Kind() == typ.Kind() {
+			return value.Convert(typ), nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch value.Kind() {
+		case reflect.Float32, reflect.Float64, reflect.Int8, reflect.Int16, reflect.Int, reflect.Int32, reflect.Int64,
+			reflect.Uint8, reflect.Uint16, reflect.Uint, reflect.Uint32, reflect.Uint64:
+			return convertNumber(value, typ)
+		}
+	case reflect.Int8, reflect.Int16, reflect.Int, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		switch value.Kind() {
+		case reflect.Int8, reflect.Int16, reflect.Int, reflect.Int32, reflect.Int64,
+			reflect.Uint8, reflect.Uint16, reflect.Uint, reflect.Uint32, reflect.Uint64:
+			return convertNumber(value, typ)
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("%s cannot be converted into %s", value.Type(), typ)
+}
+
+// convertNumber converts the given numeric value into the given numeric type
+// unless the value overflows the type.
+func convertNumber(value reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	target := reflect.Zero(typ)
+	overflows := false
+
+	switch typ.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if value.Kind() == reflect.Float32 || value.Kind() == reflect.Float64 {
+			overflows = target.OverflowFloat(value.Float())
+		}
+	case reflect.Int8, reflect.Int16, reflect.Int, reflect.Int32, reflect.Int64:
+		if value.CanInt() {
+			overflows = target.OverflowInt(value.Int())
+		} else {
+			overflows = value.Uint() > math.MaxInt64 || target.OverflowInt(int64(value.Uint()))
+		}
+	default:
+		if value.CanInt() {
+			overflows = value.Int() < 0 || target.OverflowUint(uint64(value.Int()))
+		} else {
+			overflows = target.OverflowUint(value.Uint())
+		}
+	}
+
+	if overflows {
+		return reflect.Value{}, fmt.Errorf("%v overflows %s", value, typ)
+	}
+
+	return value.Convert(typ), nil
+}
+
+func (definition *Definition) parseDynamicSyntaxFree(marker string) any {
+	output := newDynamicValue()
+	argument, exists := definition.Output.Fields[ValueArgument]
+
+	if !exists {
+		return output
+	}
+
+	text := strings.Trim(strings.Replace(marker, fmt.Sprintf("+%s", definition.Name), "", 1), " ")
+
+	if text == "" && argument.Default != nil {
+		output.set(DynamicArgument{
+			Name:      ValueArgument,
+			Value:     copyValue(reflect.ValueOf(argument.Default)).Interface(),
+			IsDefault: true,
+		})
+		return output
+	}
+
+	output.set(DynamicArgument{
+		Name:   ValueArgument,
+		Value:  text,
+		Offset: strings.Index(marker, text),
+	})
+	return output
+}
@@ -0,0 +1,287 @@
+package markers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+type requiredDefaultValueMarker struct {
+	Value string   `parameter:"Value" required:"true" default:"anyValue"`
+	Items []string `parameter:"Items" required:"true"`
+}
+
+type parameterDefaultValueMarker struct {
+	Value string   `parameter:"Value"`
+	Size  int      `parameter:"Size"`
+	Items []string `parameter:"Items"`
+}
+
+func TestMakeDefinition_InvalidDefaultValue(t *testing.T) {
+	definition, err := MakeDefinition("test:marker", "anyPkg", FieldLevel, &invalidDefaultValueMarker{})
+
+	assert.Nil(t, definition)
+	assert.Error(t, err)
+}
+
+func TestDefinition_ParseShouldApplyDefaultValues(t *testing.T) {
+	definition, err := MakeDefinition("test:marker", "anyPkg", FieldLevel, &defaultValueMarker{})
+	assert.NoError(t, err)
+
+	limit := uint(8)
+	value, err := definition.Parse("+test:marker:Size=3, Other=anyOther")
+	assert.NoError(t, err)
+	assert.Equal(t, defaultValueMarker{
+		Name:    "anyName",
+		Size:    3,
+		Enabled: true,
+		Items:   []string{"a", "b"},
+		Labels:  map[string]int{"a": 1, "b": 2},
+		Limit:   &limit,
+		Any:     []any{1, 2},
+		Other:   "anyOther",
+	}, value)
+
+	value.(defaultValueMarker).Items[0] = "changed"
+	*value.(defaultValueMarker).Limit = 3
+
+	value, err = definition.Parse("+test:marker:Other=anyOther")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, value.(defaultValueMarker).Items)
+	assert.Equal(t, uint(8), *value.(defaultValueMarker).Limit)
+}
+
+func TestDefinition_ParseShouldNotReportMissingArgumentWithDefaultValue(t *testing.T) {
+	definition, err := MakeDefinition("test:marker", "anyPkg", FieldLevel, &requiredDefaultValueMarker{})
+	assert.NoError(t, err)
+
+	value, err := definition.Parse("+test:marker:Items={x}")
+	assert.NoError(t, err)
+	assert.Equal(t, requiredDefaultValueMarker{Value: "anyValue", Items: []string{"x"}}, value)
+
+	_, err = definition.Parse("+test:marker:Value=x")
+	assert.Error(t, err)
+	assert.Equal(t, "[missing argument \"Items\"]", err.Error())
+}
+
+func TestDefinition_SetDefaultValue(t *testing.T) {
+	definition, err := MakeDefinition("test:marker", "anyPkg", FieldLevel, &parameterDefaultValueMarker{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, definition.SetDefaultValue("Value", "anyValue"))
+	assert.NoError(t, definition.SetDefaultValue("Size", "12"))
+	assert.NoError(t, definition.SetDefaultValue("Items", []any{"a", "b"}))
+
+	value, err := definition.Parse("+test:marker")
+	assert.NoError(t, err)
+	assert.Equal(t, parameterDefaultValueMarker{Value: "anyValue", Size: 12, Items: []string{"a", "b"}}, value)
+
+	assert.NoError(t, definition.SetDefaultValue("Size", 7))
+	value, err = definition.Parse("+test:marker")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, value.(parameterDefaultValueMarker).Size)
+
+	assert.Error(t, definition.SetDefaultValue("Size", true))
+	assert.Error(t, definition.SetDefaultValue("Size", "abc"))
+	assert.Error(t, definition.SetDefaultValue("Unknown", "abc"))
+}
+
+type narrowDefaultValueMarker struct {
+	Size   int8     `parameter:"Size"`
+	Limit  uint     `parameter:"Limit"`
+	Sizes  []uint16 `parameter:"Sizes"`
+	Ratio  float32  `parameter:"Ratio"`
+	Offset int64    `parameter:"Offset"`
+}
+
+func TestDefinition_SetDefaultValueShouldReturnErrorIfValueOverflows(t *testing.T) {
+	definition, err := MakeDefinition("test:marker", "anyPkg", FieldLevel, &narrowDefaultValueMarker{})
+	assert.NoError(t, err)
+
+	assert.EqualError(t, definition.SetDefaultValue("Size", 300), "default value of argument 'Size' cannot be parsed: 300 overflows int8")
+	assert.EqualError(t, definition.SetDefaultValue("Limit", -1), "default value of argument 'Limit' cannot be parsed: -1 overflows uint")
+	assert.EqualError(t, definition.SetDefaultValue("Sizes", []int{1, 70000}), "default value of argument 'Sizes' cannot be parsed: 70000 overflows uint16")
+	assert.EqualError(t, definition.SetDefaultValue("Ratio", math.MaxFloat64), "default value of argument 'Ratio' cannot be parsed: 1.7976931348623157e+308 overflows float32")
+	assert.EqualError(t, definition.SetDefaultValue("Offset", uint64(math.MaxUint64)), "default value of argument 'Offset' cannot be parsed: 18446744073709551615 overflows int64")
+
+	assert.NoError(t, definition.SetDefaultValue("Size", -128))
+	assert.NoError(t, definition.SetDefaultValue("Limit", int64(5)))
+	assert.NoError(t, definition.SetDefaultValue("Sizes", []int{1, 65535}))
+	assert.NoError(t, definition.SetDefaultValue("Ratio", 1.5))
+	assert.NoError(t, definition.SetDefaultValue("Offset", uint(9)))
+
+	value, err := definition.Parse("+test:marker")
+	assert.NoError(t, err)
+	assert.Equal(t, narrowDefaultValueMarker{Size: -128, Limit: 5, Sizes: []uint16{1, 65535}, Ratio: 1.5, Offset: 9}, value)
+}
+
+type enumMarker struct {
+	Order  string   `parameter:"Order" enum:"asc=ASCENDING,desc=DESCENDING"`
+	Orders []string `parameter:"Orders" enum:"asc=ASCENDING,desc=DESCENDING"`
+}
+
+type invalidEnumDefaultValueMarker struct {
+	Order string `parameter:"Order" enum:"asc,desc" default:"random"`
+}
+
+func TestDefinition_ParseShouldCheckEnumValues(t *testing.T) {
+	definition, err := MakeDefinition(DefinitionMarkerName, "", StructTypeLevel, &DefinitionMarker{})
+	assert.NoError(t, err)
+
+	value, err := definition.Parse("+marker:Value=any, Description=any, Targets={FIELD_LEVEL,STRUCT_TYPE_LEVEL}")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"FIELD_LEVEL", "STRUCT_TYPE_LEVEL"}, value.(DefinitionMarker).Targets)
+
+	_, err = definition.Parse("+marker:Value=any, Description=any, Targets=FOO_LEVEL")
+	assert.Error(t, err)
+
+	errorList, isErrorList := err.(ErrorList)
+	assert.True(t, isErrorList)
+	assert.Len(t, errorList, 1)
+	assert.IsType(t, ScannerError{}, errorList[0])
+	assert.Equal(t, "invalid value \"FOO_LEVEL\", allowed values are FIELD_LEVEL, FUNCTION_LEVEL, INTERFACE_METHOD_LEVEL, "+
+		"INTERFACE_TYPE_LEVEL, PACKAGE_LEVEL, STRUCT_METHOD_LEVEL, STRUCT_TYPE_LEVEL", errorList[0].Error())
+
+	_, err = definition.Parse("+marker:Value=any, Description=any, Targets=FEILD_LEVEL")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "did you mean \"FIELD_LEVEL\"?")
+}
+
+func TestDefinition_ParseShouldMapEnumValues(t *testing.T) {
+	definition, err := MakeDefinition("test:marker", "anyPkg", FieldLevel, &enumMarker{})
+	assert.NoError(t, err)
+
+	value, err := definition.Parse("+test:marker:Order=desc, Orders={asc,\"desc\"}")
+	assert.NoError(t, err)
+	assert.Equal(t, enumMarker{Order: "DESCENDING", Orders: []string{"ASCENDING", "DESCENDING"}}, value)
+
+	_, err = definition.Parse("+test:marker:Order=des")
+	assert.Error(t, err)
+	assert.Equal(t, "[invalid value \"des\", allowed values are asc, desc; did you mean \"desc\"?]", err.Error())
+
+	_, err = definition.Parse("+test:marker:Order=des, Orders={asc, \"desk\"}")
+	assert.Equal(t, ErrorList{
+		ScannerError{Message: "invalid value \"des\", allowed values are asc, desc; did you mean \"desc\"?", Offset: 19, Suggestion: "desc", Length: 3},
+		ScannerError{Message: "invalid value \"desk\", allowed values are asc, desc; did you mean \"desc\"?", Offset: 37, Suggestion: "desc", Length: 6},
+	}, err)
+}
+
+func TestMakeDefinition_InvalidEnumDefaultValue(t *testing.T) {
+	definition, err := MakeDefinition("test:marker", "anyPkg", FieldLevel, &invalidEnumDefaultValueMarker{})
+
+	assert.Nil(t, definition)
+	assert.Error(t, err)
+}
+
+func TestDefinition_ParseShouldReportUnknownArguments(t *testing.T) {
+	definition, err := MakeDefinition("test:marker", "anyPkg", FieldLevel, &parameterDefaultValueMarker{})
+	assert.NoError(t, err)
+
+	value, err := definition.Parse("+test:marker:Vlue=anyValue, Size=3, Unknown={a, b}, Items={x}")
+	assert.Equal(t, ErrorList{
+		UnknownArgumentError{Marker: "test:marker", Argument: "Vlue", Suggestion: "Value", Offset: 13},
+		UnknownArgumentError{Marker: "test:marker", Argument: "Unknown", Offset: 36},
+	}, err)
+	assert.Equal(t, parameterDefaultValueMarker{Size: 3, Items: []string{"x"}}, value)
+}
+
+func TestDefinition_ParseShouldIgnoreUnknownArgumentsIfAllowed(t *testing.T) {
+	definition, err := MakeDefinition("test:marker", "anyPkg", FieldLevel, &parameterDefaultValueMarker{})
+	assert.NoError(t, err)
+	definition.AllowUnknownArguments = true
+
+	value, err := definition.Parse("+test:marker:Value=anyValue, Unknown={a, b}, Size=3")
+	assert.NoError(t, err)
+	assert.Equal(t, parameterDefaultValueMarker{Value: "anyValue", Size: 3}, value)
+}
+
+func TestDefinition_ParseShouldReportScannerErrorOffsets(t *testing.T) {
+	definition, err := MakeDefinition("test:marker", "anyPkg", FieldLevel, &parameterDefaultValueMarker{})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		Marker string
+		Error  error
+	}{
+		{
+			Marker: "+test:marker:Items={a,b",
+			Error:  ErrorList{ScannerError{Message: "got EOF; want Right Curly Bracket '}'", Offset: 23}},
+		},
+		{
+			Marker: "+test:marker:Size=abc",
+			Error:  ErrorList{ScannerError{Message: "got \"abc\"; want Integer", Offset: 18}},
+		},
+		{
+			Marker: "+test:marker:Size 3",
+			Error:  ErrorList{ScannerError{Message: "got \"3\"; want Equal sign", Offset: 18}},
+		},
+		{
+			Marker: "+test:marker:Value=\"anyValue",
+			Error:  ErrorList{ScannerError{Message: "'\"' is missing", Offset: 19}},
+		},
+		{
+			Marker: "+test:marker:Size=3 Value=x",
+			Error:  ErrorList{ScannerError{Message: "got \"Value\"; want Comma ','", Offset: 20}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		_, err = definition.Parse(testCase.Marker)
+		assert.Equal(t, testCase.Error, err, testCase.Marker)
+	}
+}
+
+func TestDefinition_ParseShouldAllowTrailingComma(t *testing.T) {
+	definition, err := MakeDefinition("test:marker", "anyPkg", FieldLevel, &parameterDefaultValueMarker{})
+	assert.NoError(t, err)
+
+	value, err := definition.Parse("+test:marker:Size=3,")
+	assert.NoError(t, err)
+	assert.Equal(t, parameterDefaultValueMarker{Size: 3}, value)
+}
+
+type cacheMarker struct {
+	Ttl       time.Duration `parameter:"ttl" default:"1m"`
+	Rps       float64       `parameter:"rps"`
+	ExpiresAt *time.Time    `parameter:"expiresAt"`
+}
+
+func TestDefinition_ParseShouldParseFloatDurationAndTimeArguments(t *testing.T) {
+	definition, err := MakeDefinition("cache", "anyPkg", FieldLevel, &cacheMarker{})
+	assert.NoError(t, err)
+
+	expiresAt := time.Date(2022, 3, 4, 10, 20, 30, 0, time.UTC)
+	value, err := definition.Parse("+cache:ttl=30s, rps=2.5, expiresAt=2022-03-04T10:20:30Z")
+	assert.NoError(t, err)
+	assert.Equal(t, cacheMarker{Ttl: 30 * time.Second, Rps: 2.5, ExpiresAt: &expiresAt}, value)
+
+	value, err = definition.Parse("+cache:rps=3")
+	assert.NoError(t, err)
+	assert.Equal(t, cacheMarker{Ttl: time.Minute, Rps: 3}, value)
+}
+
+type dbIndexMarker struct {
+	Name    string        `parameter:"Name"`
+	Columns []indexColumn `parameter:"Columns" required:"true"`
+	Primary indexColumn   `parameter:"Primary" default:"{Name: id}"`
+}
+
+func TestDefinition_ParseShouldParseNestedStructArguments(t *testing.T) {
+	definition, err := MakeDefinition("db:index", "anyPkg", TypeLevel, &dbIndexMarker{})
+	assert.NoError(t, err)
+	assert.Equal(t, StructType, definition.Output.Fields["Primary"].TypeInfo.ActualType)
+
+	value, err := definition.Parse("+db:index:Name=idx, Columns={{Name:\"a\",Order:\"desc\"}, {Name: b}}")
+	assert.NoError(t, err)
+	assert.Equal(t, dbIndexMarker{
+		Name:    "idx",
+		Columns: []indexColumn{{Name: "a", Order: "desc"}, {Name: "b", Order: "asc"}},
+		Primary: indexColumn{Name: "id", Order: "asc"},
+	}, value)
+
+	_, err = definition.Parse("+db:index:Columns={{Name: a, Ordr: desc}}")
+	assert.Equal(t, ErrorList{
+		ScannerError{Message: "unknown field \"Ordr\"; did you mean \"Order\"?", Offset: 29},
+	}, err)
+}
@@ -0,0 +1,373 @@
+package markers
+
+import (
+	"fmt"
+	"github.com/procyon-projects/marker/packages"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// MarkerDoc is the reference documentation of a marker.
+type MarkerDoc struct {
+	CatalogMarker
+	// Syntax contains the forms the marker can be written in.
+	Syntax   []string
+	Examples []MarkerExample
+}
+
+// MarkerExample is a marker comment using a marker.
+type MarkerExample struct {
+	Text     string
+	FileName string
+	Position Position
+}
+
+// NewMarkerDoc returns the reference documentation of the given definition.
+func NewMarkerDoc(definition *Definition, examples []MarkerExample) MarkerDoc {
+	return MarkerDoc{
+		CatalogMarker: definition.Catalog(),
+		Syntax:        definition.syntaxForms(),
+		Examples:      examples,
+	}
+}
+
+// syntaxForms returns the forms the marker of the definition can be written in.
+// The optional arguments are written in square brackets.
+func (definition *Definition) syntaxForms() []string {
+	arguments := definition.arguments()
+	marker := "+" + definition.Name
+
+	if definition.Output.SyntaxFree {
+		if argument, exists := arguments[ValueArgument]; exists {
+			return []string{marker + " " + argumentSyntax(argument.TypeInfo)}
+		}
+
+		return []string{marker}
+	}
+
+	if len(arguments) == 0 {
+		return []string{marker}
+	}
+
+	names := orderedArgumentNames(arguments)
+	forms := make([]string, 0, 2)
+	fields := make([]string, 0, len(names))
+
+	for _, name := range names {
+		fields = append(fields, namedArgumentSyntax(arguments[name]))
+	}
+
+	forms = append(forms, marker+":"+strings.Join(fields, ", "))
+
+	if valueArgument, exists := arguments[ValueArgument]; exists {
+		fields = []string{"=" + argumentSyntax(valueArgument.TypeInfo)}
+
+		for _, name := range names {
+			if name != ValueArgument {
+				fields = append(fields, ", "+namedArgumentSyntax(arguments[name]))
+			}
+		}
+
+		forms = append(forms, marker+strings.Join(fields, ""))
+	}
+
+	return forms
+}
+
+// orderedArgumentNames returns the names of the given arguments in the order they are written.
+// The value argument is written first, followed by the required and the optional arguments.
+func orderedArgumentNames(arguments map[string]Argument) []string {
+	rank := func(name string) int {
+		if name == ValueArgument {
+			return 0
+		} else if arguments[name].Required {
+			return 1
+		}
+
+		return 2
+	}
+
+	names := sortedArgumentNames(arguments)
+	sort.SliceStable(names, func(i, j int) bool {
+		return rank(names[i]) < rank(names[j])
+	})
+
+	return names
+}
+
+func namedArgumentSyntax(argument Argument) string {
+	text := argument.Name + "=" + argumentSyntax(argument.TypeInfo)
+
+	if !argument.Required {
+		return "[" + text + "]"
+	}
+
+	return text
+}
+
+// argumentSyntax returns a placeholder describing the values of the given type.
+func argumentSyntax(typeInfo ArgumentTypeInfo) string {
+	switch typeInfo.ActualType {
+	case BoolType:
+		return "true|false"
+	case SignedIntegerType:
+		return "<int>"
+	case UnsignedIntegerType:
+		return "<uint>"
+	case FloatType:
+		return "<float>"
+	case StringType:
+		if len(typeInfo.Enum) != 0 {
+			values := make([]string, 0, len(typeInfo.Enum))
+
+			for value := range typeInfo.Enum {
+				values = append(values, value)
+			}
+
+			sort.Strings(values)
+			return strings.Join(values, "|")
+		}
+
+		return "<string>"
+	case DurationType:
+		return "<duration>"
+	case TimeType:
+		return "<time>"
+	case GoType:
+		return "<type>"
+	case GoFuncType:
+		return "<func>"
+	case SliceType:
+		if typeInfo.ItemType != nil {
+			return "{" + argumentSyntax(*typeInfo.ItemType) + ", ...}"
+		}
+	case MapType:
+		if typeInfo.ItemType != nil {
+			return "{<key>: " + argumentSyntax(*typeInfo.ItemType) + ", ...}"
+		}
+	case StructType:
+		fields := make([]string, 0, len(typeInfo.Fields))
+
+		for _, name := range sortedArgumentNames(typeInfo.Fields) {
+			fields = append(fields, name+": "+argumentSyntax(typeInfo.Fields[name].TypeInfo))
+		}
+
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+
+	return "<any>"
+}
+
+// FindMarkerExamples returns the marker comments using the marker of the given definition
+// in the given packages. The names written under the aliases of the import markers are resolved
+// per file, and the markers imported from the other packages are skipped. The examples are sorted
+// by their positions, at most limit examples are returned unless the limit is zero or negative.
+func FindMarkerExamples(pkgs []*packages.Package, definition *Definition, limit int) []MarkerExample {
+	examples := make([]MarkerExample, 0)
+	seen := make(map[string]struct{})
+	registry := NewRegistry()
+
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			importAliases := fileImportAliases(registry, newCommentVisitor(file.Comments).getMarkerComments(0, len(file.Comments)))
+
+			for _, commentGroup := range file.Comments {
+				for _, comment := range commentGroup.List {
+					if !isMarkerComment(comment.Text) {
+						continue
+					}
+
+					text, _ := markerCommentLine(comment)
+
+					if !isMarkerExample(definition, text, pkg.PkgPath, importAliases) {
+						continue
+					}
+
+					if _, exists := seen[text]; exists {
+						continue
+					}
+
+					seen[text] = struct{}{}
+					position := pkg.Fset.Position(comment.Pos())
+					examples = append(examples, MarkerExample{
+						Text:     text,
+						FileName: position.Filename,
+						Position: Position{
+							Line:   position.Line,
+							Column: position.Column,
+						},
+					})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(examples, func(i, j int) bool {
+		if examples[i].FileName != examples[j].FileName {
+			return examples[i].FileName < examples[j].FileName
+		}
+
+		return examples[i].Position.Line < examples[j].Position.Line
+	})
+
+	if limit > 0 && len(examples) > limit {
+		examples = examples[:limit]
+	}
+
+	return examples
+}
+
+// isMarkerExample checks if the given marker text uses the marker of the definition. The markers
+// without an import are only taken in the package of the definition or if they are reserved.
+func isMarkerExample(definition *Definition, text, pkgPath string, importAliases AliasMap) bool {
+	name, anonymousName, _ := splitMarker(text)
+	alias := strings.SplitN(name, ":", 2)[0]
+
+	if importMarker, ok := importAliases[alias]; ok {
+		if importMarker.PkgPath() != definition.Package {
+			return false
+		}
+
+		name = importMarker.Value + strings.TrimPrefix(name, alias)
+		anonymousName = importMarker.Value + strings.TrimPrefix(anonymousName, alias)
+	} else if definition.Package != "" && definition.Package != pkgPath {
+		return false
+	}
+
+	return name == definition.Name || anonymousName == definition.Name
+}
+
+var docFunctions = map[string]any{
+	"join": strings.Join,
+	"kind": func(typ CatalogType) string {
+		return strings.TrimSuffix(typ.Kind, "Type")
+	},
+	"enum": func(typ CatalogType) []string {
+		for typ.Item != nil && len(typ.Enum) == 0 {
+			typ = *typ.Item
+		}
+
+		values := make([]string, 0, len(typ.Enum))
+
+		for value := range typ.Enum {
+			values = append(values, value)
+		}
+
+		sort.Strings(values)
+		return values
+	},
+	"value": func(value any) string {
+		return docValueText(catalogValue(value))
+	},
+}
+
+// docValueText returns the text of the given catalog value as it is written in the marker comments.
+func docValueText(value any) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return ""
+	case []any:
+		items := make([]string, 0, len(typedValue))
+
+		for _, item := range typedValue {
+			items = append(items, docValueText(item))
+		}
+
+		return "{" + strings.Join(items, ", ") + "}"
+	case map[string]any:
+		keys := make([]string, 0, len(typedValue))
+
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+		items := make([]string, 0, len(keys))
+
+		for _, key := range keys {
+			items = append(items, key+": "+docValueText(typedValue[key]))
+		}
+
+		return "{" + strings.Join(items, ", ") + "}"
+	}
+
+	return fmt.Sprint(value)
+}
+
+var markdownDocTemplate = template.Must(template.New("markdown").Funcs(docFunctions).Parse(`# {{ .Name }}
+{{ if .Description }}
+{{ .Description }}
+{{ end }}{{ if .Deprecated }}
+> **Deprecated:** {{ if .DeprecationMessage }}{{ .DeprecationMessage }}{{ else }}this marker is deprecated.{{ end }}
+{{ end }}
+| Package | Targets | Repeatable |
+| --- | --- | --- |
+| ` + "`{{ if .Package }}{{ .Package }}{{ else }}builtin{{ end }}`" + ` | {{ join .Targets ", " }} | {{ .Repeatable }} |
+
+## Syntax
+
+` + "```" + `
+{{ range .Syntax }}{{ . }}
+{{ end }}` + "```" + `
+{{ if .Arguments }}
+## Arguments
+
+| Name | Type | Required | Default | Description |
+| --- | --- | --- | --- | --- |
+{{ range .Arguments }}| ` + "`{{ .Name }}`" + ` | {{ kind .Type }} | {{ .Required }} | {{ with value .Default }}` + "`{{ . }}`" + `{{ end }} | {{ if .Deprecated }}**Deprecated.** {{ end }}{{ .Description }}{{ with enum .Type }} Values: {{ range $index, $value := . }}{{ if $index }}, {{ end }}` + "`{{ $value }}`" + `{{ end }}.{{ end }} |
+{{ end }}{{ end }}{{ if .Examples }}
+## Examples
+
+` + "```go" + `
+{{ range .Examples }}// {{ .Text }}
+{{ end }}` + "```" + `
+{{ end }}`))
+
+var htmlDocTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(docFunctions).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Name }}</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #222; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ddd; padding: 0.4em 0.6em; text-align: left; vertical-align: top; }
+pre { background: #f6f8fa; padding: 1em; overflow-x: auto; }
+.deprecated { color: #b00; }
+</style>
+</head>
+<body>
+<h1>{{ .Name }}</h1>
+{{ if .Description }}<p>{{ .Description }}</p>
+{{ end }}{{ if .Deprecated }}<p class="deprecated"><strong>Deprecated:</strong> {{ if .DeprecationMessage }}{{ .DeprecationMessage }}{{ else }}this marker is deprecated.{{ end }}</p>
+{{ end }}<table>
+<tr><th>Package</th><th>Targets</th><th>Repeatable</th></tr>
+<tr><td><code>{{ if .Package }}{{ .Package }}{{ else }}builtin{{ end }}</code></td><td>{{ join .Targets ", " }}</td><td>{{ .Repeatable }}</td></tr>
+</table>
+<h2>Syntax</h2>
+<pre>{{ range .Syntax }}{{ . }}
+{{ end }}</pre>
+{{ if .Arguments }}<h2>Arguments</h2>
+<table>
+<tr><th>Name</th><th>Type</th><th>Required</th><th>Default</th><th>Description</th></tr>
+{{ range .Arguments }}<tr><td><code>{{ .Name }}</code></td><td>{{ kind .Type }}</td><td>{{ .Required }}</td><td>{{ with value .Default }}<code>{{ . }}</code>{{ end }}</td><td>{{ if .Deprecated }}<strong class="deprecated">Deprecated.</strong> {{ end }}{{ .Description }}{{ with enum .Type }} Values: {{ range $index, $value := . }}{{ if $index }}, {{ end }}<code>{{ $value }}</code>{{ end }}.{{ end }}</td></tr>
+{{ end }}</table>
+{{ end }}{{ if .Examples }}<h2>Examples</h2>
+<pre>{{ range .Examples }}// {{ .Text }}
+{{ end }}</pre>
+{{ end }}</body>
+</html>
+`))
+
+// WriteMarkdown writes the documentation as a Markdown page.
+func (doc MarkerDoc) WriteMarkdown(writer io.Writer) error {
+	return markdownDocTemplate.Execute(writer, doc)
+}
+
+// WriteHTML writes the documentation as a standalone HTML page.
+func (doc MarkerDoc) WriteHTML(writer io.Writer) error {
+	return htmlDocTemplate.Execute(writer, doc)
+}
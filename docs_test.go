@@ -0,0 +1,143 @@
+package markers
+
+import (
+	"bytes"
+	"github.com/procyon-projects/marker/packages"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const docsSource = definitionSource + `
+// +cache:Order=asc, Tags={users}
+func FindUsers() {}
+
+// +cache:Order=desc
+func FindOrders() {}
+
+// +cache:Order=asc, Tags={users}
+func FindAdmins() {}
+`
+
+func TestDefinition_SyntaxForms(t *testing.T) {
+	definition, err := MakeDefinition("import", "", PackageLevel, &ImportMarker{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"+import:Value=<string>, Pkg=<string>, [Alias=<string>]",
+		"+import=<string>, Pkg=<string>, [Alias=<string>]",
+	}, definition.syntaxForms())
+
+	definition, err = MakeDefinition("deprecated", "", FieldLevel, &DeprecatedMarker{})
+	assert.NoError(t, err)
+	definition.Output.SyntaxFree = true
+	assert.Equal(t, []string{"+deprecated <string>"}, definition.syntaxForms())
+
+	definition, err = MakeDefinition("test:index", "anyPkg", StructTypeLevel, &dbIndexMarker{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"+test:index:Columns={{Name: <string>, Order: asc|desc, Size: <int>}, ...}, [Name=<string>], [Primary={Name: <string>, Order: asc|desc, Size: <int>}]",
+	}, definition.syntaxForms())
+}
+
+func TestFindMarkerExamples(t *testing.T) {
+	pkg := newTestPackage(t, docsSource)
+	definitions, err := LoadDefinitions(pkg)
+	assert.NoError(t, err)
+
+	examples := FindMarkerExamples([]*packages.Package{pkg}, definitions[0], 0)
+	assert.Len(t, examples, 2)
+	assert.Equal(t, "+cache:Order=asc, Tags={users}", examples[0].Text)
+	assert.Equal(t, Position{Line: 30, Column: 1}, examples[0].Position)
+	assert.Equal(t, "+cache:Order=desc", examples[1].Text)
+
+	assert.Len(t, FindMarkerExamples([]*packages.Package{pkg}, definitions[0], 1), 1)
+}
+
+func TestFindMarkerExamplesShouldResolveImportAliases(t *testing.T) {
+	definitions, err := LoadDefinitions(newTestPackage(t, definitionSource))
+	assert.NoError(t, err)
+
+	aliasedPkg := newTestPackage(t, `
+// +import=cache, Pkg=github.com/procyon-projects/marker/test/source, Alias=c
+
+package usage
+
+// +c:Order=asc, Tags={orders}
+func FindOrders() {}
+
+// +cache:Order=desc
+func FindUsers() {}
+`)
+	aliasedPkg.PkgPath = "github.com/procyon-projects/marker/test/usage"
+
+	otherPkg := newTestPackage(t, `
+// +import=cache, Pkg=github.com/procyon-projects/other
+
+package other
+
+// +cache:Order=asc
+func FindAdmins() {}
+`)
+	otherPkg.PkgPath = "github.com/procyon-projects/marker/test/other"
+
+	examples := FindMarkerExamples([]*packages.Package{aliasedPkg, otherPkg}, definitions[0], 0)
+	assert.Len(t, examples, 1)
+	assert.Equal(t, "+c:Order=asc, Tags={orders}", examples[0].Text)
+	assert.Equal(t, Position{Line: 6, Column: 1}, examples[0].Position)
+}
+
+func TestMarkerDoc_WriteMarkdown(t *testing.T) {
+	pkg := newTestPackage(t, docsSource)
+	definitions, err := LoadDefinitions(pkg)
+	assert.NoError(t, err)
+
+	var buffer bytes.Buffer
+	doc := NewMarkerDoc(definitions[0], FindMarkerExamples([]*packages.Package{pkg}, definitions[0], 1))
+	assert.NoError(t, doc.WriteMarkdown(&buffer))
+	assert.Equal(t, "# cache\n"+
+		"\n"+
+		"caches the results\n"+
+		"\n"+
+		"> **Deprecated:** use memo instead\n"+
+		"\n"+
+		"| Package | Targets | Repeatable |\n"+
+		"| --- | --- | --- |\n"+
+		"| `github.com/procyon-projects/marker/test/source` | FUNCTION_LEVEL, STRUCT_METHOD_LEVEL | true |\n"+
+		"\n"+
+		"## Syntax\n"+
+		"\n"+
+		"```\n"+
+		"+cache:Order=asc|desc, [Index={Name: <string>, Size: <int>}], [Tags={<string>, ...}], [ttl=<duration>]\n"+
+		"```\n"+
+		"\n"+
+		"## Arguments\n"+
+		"\n"+
+		"| Name | Type | Required | Default | Description |\n"+
+		"| --- | --- | --- | --- | --- |\n"+
+		"| `Index` | Struct | false |  |  |\n"+
+		"| `Order` | String | true |  | order of the entries Values: `asc`, `desc`. |\n"+
+		"| `Tags` | Slice | false |  | **Deprecated.** tags of the entries |\n"+
+		"| `ttl` | Duration | false | `1m0s` | time to live |\n"+
+		"\n"+
+		"## Examples\n"+
+		"\n"+
+		"```go\n"+
+		"// +cache:Order=asc, Tags={users}\n"+
+		"```\n", buffer.String())
+}
+
+func TestMarkerDoc_WriteHTML(t *testing.T) {
+	definition, err := MakeDefinition("import", "", PackageLevel, &ImportMarker{})
+	assert.NoError(t, err)
+	definition.Description = "imports <processors>"
+
+	var buffer bytes.Buffer
+	assert.NoError(t, NewMarkerDoc(definition, nil).WriteHTML(&buffer))
+
+	html := buffer.String()
+	assert.Contains(t, html, "<!DOCTYPE html>")
+	assert.Contains(t, html, "<title>import</title>")
+	assert.Contains(t, html, "<p>imports &lt;processors&gt;</p>")
+	assert.Contains(t, html, "&#43;import=&lt;string&gt;, Pkg=&lt;string&gt;, [Alias=&lt;string&gt;]")
+	assert.Contains(t, html, "<tr><td><code>Pkg</code></td><td>String</td><td>true</td><td></td><td></td></tr>")
+	assert.NotContains(t, html, "<h2>Examples</h2>")
+}
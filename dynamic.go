@@ -0,0 +1,171 @@
+package markers
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+// DynamicArgument is an argument of a dynamic marker value.
+type DynamicArgument struct {
+	Name  string
+	Value any
+	// Offset is the offset of the argument within the marker text.
+	Offset int
+	// Position is the position of the argument in the source file,
+	// it is only available for the values returned by the collector.
+	Position Position
+	// IsDefault reports whether the argument is absent in the marker
+	// text and its default value is used.
+	IsDefault bool
+}
+
+// DynamicValue is the output of the dynamic definitions. It keeps the arguments in the
+// order they appear in the marker text, followed by the arguments having default values.
+type DynamicValue struct {
+	arguments []DynamicArgument
+	indexes   map[string]int
+}
+
+func newDynamicValue() *DynamicValue {
+	return &DynamicValue{
+		indexes: make(map[string]int),
+	}
+}
+
+// Len returns the number of the arguments.
+func (value *DynamicValue) Len() int {
+	return len(value.arguments)
+}
+
+// Names returns the names of the arguments in order.
+func (value *DynamicValue) Names() []string {
+	names := make([]string, 0, len(value.arguments))
+
+	for _, argument := range value.arguments {
+		names = append(names, argument.Name)
+	}
+
+	return names
+}
+
+// Arguments returns the arguments in order.
+func (value *DynamicValue) Arguments() []DynamicArgument {
+	arguments := make([]DynamicArgument, len(value.arguments))
+	copy(arguments, value.arguments)
+	return arguments
+}
+
+// Argument returns the argument with the given name.
+func (value *DynamicValue) Argument(name string) (DynamicArgument, bool) {
+	index, exists := value.indexes[name]
+
+	if !exists {
+		return DynamicArgument{}, false
+	}
+
+	return value.arguments[index], true
+}
+
+// Get returns the value of the argument with the given name.
+func (value *DynamicValue) Get(name string) (any, bool) {
+	argument, exists := value.Argument(name)
+	return argument.Value, exists
+}
+
+func (value *DynamicValue) set(argument DynamicArgument) {
+	if index, exists := value.indexes[argument.Name]; exists {
+		value.arguments[index] = argument
+		return
+	}
+
+	value.indexes[argument.Name] = len(value.arguments)
+	value.arguments = append(value.arguments, argument)
+}
+
+// setPositions sets the positions of the arguments which are present in the marker comment.
+func (value *DynamicValue) setPositions(comment markerComment, fileSet *token.FileSet) {
+	for index, argument := range value.arguments {
+		if argument.IsDefault {
+			continue
+		}
+
+		position := fileSet.Position(comment.offsetPos(argument.Offset))
+		value.arguments[index].Position = Position{
+			Line:   position.Line,
+			Column: position.Column,
+		}
+	}
+}
+
+// MakeDynamicDefinition returns a definition which does not have a Go output type.
+// The values of its markers are parsed into *DynamicValue by using the type information
+// of the given arguments. The default values of the arguments are set as in SetDefaultValue.
+func MakeDynamicDefinition(name, pkg string, level TargetLevel, arguments ...Argument) (*Definition, error) {
+	if len(strings.TrimSpace(name)) == 0 {
+		return nil, errors.New("marker name cannot be empty")
+	}
+
+	definition := &Definition{
+		Name:        strings.TrimSpace(name),
+		Package:     strings.TrimSpace(pkg),
+		TargetLevel: level,
+		Output: Output{
+			IsDynamic:  true,
+			Fields:     make(map[string]Argument),
+			FieldNames: make(map[string]string),
+		},
+	}
+
+	for _, argument := range arguments {
+		if strings.TrimSpace(argument.Name) == "" {
+			return nil, errors.New("argument name cannot be empty")
+		}
+
+		if _, exists := definition.Output.Fields[argument.Name]; exists {
+			return nil, fmt.Errorf("argument '%s' is already defined", argument.Name)
+		}
+
+		if argument.TypeInfo.ActualType == RawType {
+			return nil, errors.New("RawArgument cannot be a field")
+		}
+
+		if _, err := argument.TypeInfo.dynamicType(); err != nil {
+			return nil, fmt.Errorf("argument '%s' is not valid: %w", argument.Name, err)
+		}
+
+		defaultValue := argument.Default
+		argument.Default = nil
+		definition.Output.Fields[argument.Name] = argument
+
+		if defaultValue == nil {
+			continue
+		}
+
+		err := definition.SetDefaultValue(argument.Name, defaultValue)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := definition.validate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return definition, nil
+}
+
+// argumentType returns the Go type which the value of the argument is parsed into.
+func (definition *Definition) argumentType(argumentName string) (reflect.Type, error) {
+	if definition.Output.IsDynamic {
+		return definition.Output.Fields[argumentName].TypeInfo.dynamicType()
+	}
+
+	field, _ := definition.Output.Type.FieldByName(definition.Output.FieldNames[argumentName])
+	return field.Type, nil
+}
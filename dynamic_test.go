@@ -0,0 +1,166 @@
+package markers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newDynamicCacheDefinition(t *testing.T) *Definition {
+	definition, err := MakeDynamicDefinition("cache", "anyPkg", FunctionLevel,
+		Argument{Name: "Name", TypeInfo: ArgumentTypeInfo{ActualType: StringType}, Required: true},
+		Argument{Name: "Ttl", TypeInfo: ArgumentTypeInfo{ActualType: DurationType}, Default: "1m"},
+		Argument{Name: "Size", TypeInfo: ArgumentTypeInfo{ActualType: UnsignedIntegerType}},
+		Argument{Name: "Tags", TypeInfo: ArgumentTypeInfo{ActualType: SliceType, ItemType: &ArgumentTypeInfo{ActualType: StringType}}},
+		Argument{Name: "Order", TypeInfo: ArgumentTypeInfo{ActualType: StringType, Enum: map[string]any{"asc": "ASC", "desc": "DESC"}}},
+	)
+	assert.NoError(t, err)
+	return definition
+}
+
+func TestMakeDynamicDefinition(t *testing.T) {
+	definition := newDynamicCacheDefinition(t)
+
+	assert.True(t, definition.Output.IsDynamic)
+	assert.Nil(t, definition.Output.Type)
+	assert.Equal(t, []string{"Name", "Order", "Size", "Tags", "Ttl"}, sortedArgumentNames(definition.Output.Fields))
+	assert.Equal(t, time.Minute, definition.Output.Fields["Ttl"].Default)
+}
+
+func TestMakeDynamicDefinition_InvalidArguments(t *testing.T) {
+	_, err := MakeDynamicDefinition("cache", "anyPkg", FunctionLevel,
+		Argument{Name: "Name", TypeInfo: ArgumentTypeInfo{ActualType: StringType}},
+		Argument{Name: "Name", TypeInfo: ArgumentTypeInfo{ActualType: BoolType}},
+	)
+	assert.EqualError(t, err, "argument 'Name' is already defined")
+
+	_, err = MakeDynamicDefinition("cache", "anyPkg", FunctionLevel,
+		Argument{Name: "Tags", TypeInfo: ArgumentTypeInfo{ActualType: SliceType}},
+	)
+	assert.EqualError(t, err, "argument 'Tags' is not valid: item type cannot be nil for SliceType")
+
+	_, err = MakeDynamicDefinition("cache", "anyPkg", FunctionLevel,
+		Argument{Name: "Ttl", TypeInfo: ArgumentTypeInfo{ActualType: DurationType}, Default: "soon"},
+	)
+	assert.EqualError(t, err, "default value of argument 'Ttl' cannot be parsed: expected duration, got \"soon\"")
+
+	_, err = MakeDynamicDefinition("Cache", "anyPkg", FunctionLevel)
+	assert.EqualError(t, err, "marker 'Cache' should only contain lower case characters")
+}
+
+func TestDefinition_ParseDynamic(t *testing.T) {
+	definition := newDynamicCacheDefinition(t)
+
+	value, err := definition.Parse("+cache:Tags={a, b}, Name=users, Order=desc, Size=8")
+	assert.NoError(t, err)
+
+	output := value.(*DynamicValue)
+	assert.Equal(t, 5, output.Len())
+	assert.Equal(t, []string{"Tags", "Name", "Order", "Size", "Ttl"}, output.Names())
+
+	tags, _ := output.Argument("Tags")
+	assert.Equal(t, DynamicArgument{Name: "Tags", Value: []string{"a", "b"}, Offset: 7}, tags)
+
+	name, _ := output.Argument("Name")
+	assert.Equal(t, DynamicArgument{Name: "Name", Value: "users", Offset: 20}, name)
+
+	order, _ := output.Get("Order")
+	assert.Equal(t, "DESC", order)
+
+	size, _ := output.Get("Size")
+	assert.Equal(t, uint(8), size)
+
+	ttl, _ := output.Argument("Ttl")
+	assert.Equal(t, DynamicArgument{Name: "Ttl", Value: time.Minute, IsDefault: true}, ttl)
+
+	_, exists := output.Get("Other")
+	assert.False(t, exists)
+}
+
+func TestDefinition_ParseDynamicShouldReportErrors(t *testing.T) {
+	definition := newDynamicCacheDefinition(t)
+
+	_, err := definition.Parse("+cache:Size=8, Nmae=users")
+	assert.Equal(t, ErrorList{
+		UnknownArgumentError{Marker: "cache", Argument: "Nmae", Suggestion: "Name", Offset: 15},
+		ScannerError{Message: "missing argument \"Name\""},
+	}, err)
+
+	_, err = definition.Parse("+cache:Name=users, Order=random")
+	assert.EqualError(t, err, "[invalid value \"random\", allowed values are asc, desc]")
+}
+
+func TestDefinition_ParseDynamicValueSyntax(t *testing.T) {
+	definition, err := MakeDynamicDefinition("qualifier", "anyPkg", FieldLevel,
+		Argument{Name: "Value", TypeInfo: ArgumentTypeInfo{ActualType: AnyType}},
+	)
+	assert.NoError(t, err)
+
+	value, err := definition.Parse("+qualifier={1, 2}")
+	assert.NoError(t, err)
+
+	argument, _ := value.(*DynamicValue).Argument("Value")
+	assert.Equal(t, DynamicArgument{Name: "Value", Value: []any{1, 2}, Offset: 11}, argument)
+
+	definition.Output.SyntaxFree = true
+
+	value, err = definition.Parse("+qualifier some text")
+	assert.NoError(t, err)
+
+	argument, _ = value.(*DynamicValue).Argument("Value")
+	assert.Equal(t, DynamicArgument{Name: "Value", Value: "some text", Offset: 11}, argument)
+}
+
+func TestDefinition_ParseDynamicStruct(t *testing.T) {
+	columnTypeInfo, err := ArgumentTypeInfoFromType(reflect.TypeOf(indexColumn{}))
+	assert.NoError(t, err)
+
+	definition, err := MakeDynamicDefinition("index", "anyPkg", StructTypeLevel,
+		Argument{Name: "Column", TypeInfo: columnTypeInfo},
+	)
+	assert.NoError(t, err)
+
+	value, err := definition.Parse("+index:Column={Name: id, Order: desc}")
+	assert.NoError(t, err)
+
+	column, _ := value.(*DynamicValue).Get("Column")
+	assert.Equal(t, "id", reflect.ValueOf(column).FieldByName("Name").Interface())
+	assert.Equal(t, "desc", reflect.ValueOf(column).FieldByName("Order").Interface())
+}
+
+func TestCollector_CollectDynamicMarkers(t *testing.T) {
+	definition, err := MakeDynamicDefinition("test:provide", "github.com/procyon-projects/test", FunctionLevel,
+		Argument{Name: "Type", TypeInfo: ArgumentTypeInfo{ActualType: GoType}, Required: true},
+		Argument{Name: "Lazy", TypeInfo: ArgumentTypeInfo{ActualType: BoolType}, Default: false},
+	)
+	assert.NoError(t, err)
+
+	registry := NewRegistry()
+	assert.NoError(t, registry.RegisterWithDefinition(definition))
+
+	nodes, err := NewCollector(registry).Collect(newTestPackage(t, `
+// +import=test, Pkg=github.com/procyon-projects/test
+
+package source
+
+type Service struct {
+}
+
+// +test:provide:Type=Service
+func NewService() *Service {
+	return &Service{}
+}
+`))
+	assert.NoError(t, err)
+
+	value := findMarkerValues(nodes, "NewService").First("test:provide").(*DynamicValue)
+	argument, _ := value.Argument("Type")
+	assert.Equal(t, Position{Line: 9, Column: 18}, argument.Position)
+	assert.Equal(t, "Service", argument.Value.(TypeReference).Name)
+	assert.IsType(t, &types.TypeName{}, argument.Value.(TypeReference).Object)
+
+	lazy, _ := value.Argument("Lazy")
+	assert.Equal(t, DynamicArgument{Name: "Lazy", Value: false, IsDefault: true}, lazy)
+}
@@ -1,6 +1,7 @@
 package markers
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/token"
@@ -25,8 +26,19 @@ func NewError(err error, fileName string, position Position) error {
 	}
 }
 
+// Unwrap returns the underlying error.
+func (err Error) Unwrap() error {
+	return err.error
+}
+
+// ScannerError is returned when a marker comment cannot be scanned. Offset is
+// the byte offset of the token causing the error within the marker text.
+// Suggestion is the text which can replace the token of the given length to fix the error.
 type ScannerError struct {
-	Message string
+	Message    string
+	Offset     int
+	Suggestion string
+	Length     int
 }
 
 func (err ScannerError) Error() string {
@@ -41,12 +53,57 @@ func (err ImportError) Error() string {
 	return fmt.Sprintf("the marker '%s' cannot be resolved", err.Marker)
 }
 
+type DuplicateMarkerError struct {
+	Marker   string
+	FileName string
+	Position Position
+}
+
+func (err DuplicateMarkerError) Error() string {
+	return fmt.Sprintf("the marker '%s' is not repeatable, it has already been declared at %s:%d:%d", err.Marker, err.FileName, err.Position.Line, err.Position.Column)
+}
+
+// UnknownArgumentError is returned when a marker comment contains an argument
+// which does not exist in the definition. Offset is the byte offset of the argument
+// within the marker text.
+type UnknownArgumentError struct {
+	Marker     string
+	Argument   string
+	Suggestion string
+	Offset     int
+}
+
+func (err UnknownArgumentError) Error() string {
+	if err.Suggestion != "" {
+		return fmt.Sprintf("the marker '%s' does not have an argument named '%s', did you mean '%s'?", err.Marker, err.Argument, err.Suggestion)
+	}
+
+	return fmt.Sprintf("the marker '%s' does not have an argument named '%s'", err.Marker, err.Argument)
+}
+
+// ReferenceError is returned when a Go symbol referenced by a marker cannot be resolved.
+// Offset is the byte offset of the reference within the marker text.
+type ReferenceError struct {
+	Reference string
+	Reason    string
+	Offset    int
+}
+
+func (err ReferenceError) Error() string {
+	return fmt.Sprintf("the reference '%s' cannot be resolved: %s", err.Reference, err.Reason)
+}
+
 type ParserError struct {
 	FileName string
 	Position Position
 	error
 }
 
+// Unwrap returns the underlying error.
+func (err ParserError) Unwrap() error {
+	return err.error
+}
+
 type ErrorList []error
 
 func NewErrorList(errors []error) error {
@@ -57,6 +114,27 @@ func NewErrorList(errors []error) error {
 	return ErrorList(errors)
 }
 
+// FlattenErrors returns the errors in the given error and the nested error lists.
+func FlattenErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	errorList, isErrorList := err.(ErrorList)
+
+	if !isErrorList {
+		return []error{err}
+	}
+
+	errs := make([]error, 0, len(errorList))
+
+	for _, element := range errorList {
+		errs = append(errs, FlattenErrors(element)...)
+	}
+
+	return errs
+}
+
 func (errorList ErrorList) ToErrors() []error {
 	return errorList
 }
@@ -88,3 +166,35 @@ func toParseError(err error, node ast.Node, position token.Position) error {
 
 	return errors
 }
+
+// toMarkerParseError converts the given error into a parser error. The errors which know
+// their offsets within the marker text are positioned at the exact line and column.
+func toMarkerParseError(err error, comment markerComment, fileSet *token.FileSet) error {
+	errorList, ok := err.(ErrorList)
+
+	if ok {
+		parseErrors := make(ErrorList, len(errorList))
+
+		for index, errorElement := range errorList {
+			parseErrors[index] = toMarkerParseError(errorElement, comment, fileSet)
+		}
+
+		return parseErrors
+	}
+
+	position := fileSet.Position(comment.Pos())
+
+	var scannerErr ScannerError
+	var unknownArgumentErr UnknownArgumentError
+	var referenceErr ReferenceError
+
+	if errors.As(err, &scannerErr) {
+		position = fileSet.Position(comment.offsetPos(scannerErr.Offset))
+	} else if errors.As(err, &unknownArgumentErr) {
+		position = fileSet.Position(comment.offsetPos(unknownArgumentErr.Offset))
+	} else if errors.As(err, &referenceErr) {
+		position = fileSet.Position(comment.offsetPos(referenceErr.Offset))
+	}
+
+	return toParseError(err, comment, position)
+}
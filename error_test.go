@@ -2,7 +2,9 @@ package markers
 
 import (
 	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
+	"go/ast"
 	"go/token"
 	"testing"
 )
@@ -54,6 +56,27 @@ func TestToParseError(t *testing.T) {
 	assert.Equal(t, Position{Line: 10, Column: 13}, parserError.Position)
 }
 
+func TestToMarkerParseErrorWithWrappedError(t *testing.T) {
+	fileSet := token.NewFileSet()
+	file := fileSet.AddFile("anyFileName", -1, 100)
+	comment := markerComment{
+		commentLines: []*ast.Comment{{Slash: file.Pos(0), Text: "// +marker:anyMarker=anyValue"}},
+	}
+
+	wrappedErrors := []error{
+		fmt.Errorf("wrapped: %w", ScannerError{Message: "anyError", Offset: 19}),
+		fmt.Errorf("wrapped: %w", UnknownArgumentError{Offset: 19}),
+		fmt.Errorf("wrapped: %w", ReferenceError{Offset: 19}),
+	}
+
+	for _, err := range wrappedErrors {
+		parserError, isParserError := toMarkerParseError(err, comment, fileSet).(ParserError)
+		assert.True(t, isParserError)
+		assert.Equal(t, err, parserError.error)
+		assert.Equal(t, Position{Line: 1, Column: 23}, parserError.Position)
+	}
+}
+
 func TestErrorList_ToErrors(t *testing.T) {
 	errorSlice := []error{errors.New("anyError1"), errors.New("anyError2")}
 	anyErrorList := NewErrorList(errorSlice)
@@ -65,6 +88,16 @@ func TestErrorList_Error(t *testing.T) {
 	assert.Equal(t, "[anyError1 anyError2]", anyErrorList.Error())
 }
 
+func TestFlattenErrors(t *testing.T) {
+	anyError1 := errors.New("anyError1")
+	anyError2 := errors.New("anyError2")
+	anyError3 := errors.New("anyError3")
+
+	assert.Nil(t, FlattenErrors(nil))
+	assert.Equal(t, []error{anyError1}, FlattenErrors(anyError1))
+	assert.Equal(t, []error{anyError1, anyError2, anyError3}, FlattenErrors(NewErrorList([]error{anyError1, NewErrorList([]error{anyError2, anyError3})})))
+}
+
 func TestToParseErrorWithErrorList(t *testing.T) {
 	anyErrorList := NewErrorList([]error{errors.New("anyError1"), errors.New("anyError2")})
 
@@ -95,3 +128,36 @@ func TestToParseErrorWithErrorList(t *testing.T) {
 	assert.Equal(t, "anyFileName", parserError.FileName)
 	assert.Equal(t, Position{Line: 10, Column: 13}, parserError.Position)
 }
+
+func TestDuplicateMarkerError_Error(t *testing.T) {
+	duplicateMarkerErr := &DuplicateMarkerError{
+		Marker:   "anyMarker",
+		FileName: "anyFileName",
+		Position: Position{
+			Line:   3,
+			Column: 1,
+		},
+	}
+	assert.Equal(t, "the marker 'anyMarker' is not repeatable, it has already been declared at anyFileName:3:1", duplicateMarkerErr.Error())
+}
+
+func TestUnknownArgumentError_Error(t *testing.T) {
+	err := UnknownArgumentError{Marker: "test:marker", Argument: "Nmae", Suggestion: "Name"}
+	assert.Equal(t, "the marker 'test:marker' does not have an argument named 'Nmae', did you mean 'Name'?", err.Error())
+
+	err = UnknownArgumentError{Marker: "test:marker", Argument: "Unknown"}
+	assert.Equal(t, "the marker 'test:marker' does not have an argument named 'Unknown'", err.Error())
+}
+
+func TestParserError_Unwrap(t *testing.T) {
+	err := toParseError(ReferenceError{Reference: "Service", Reason: "'Service' is not declared"}, nil, token.Position{Line: 3, Column: 4})
+
+	var referenceErr ReferenceError
+	assert.True(t, errors.As(err, &referenceErr))
+	assert.Equal(t, "Service", referenceErr.Reference)
+
+	err = NewError(ImportError{Marker: "test"}, "anyFileName", Position{})
+
+	var importErr ImportError
+	assert.True(t, errors.As(err, &importErr))
+}
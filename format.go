@@ -0,0 +1,587 @@
+package markers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultFormatWidth is the default maximum width of the formatted marker comment lines.
+const DefaultFormatWidth = 100
+
+// FormatOptions configures how the marker comments are formatted.
+type FormatOptions struct {
+	// Width is the maximum width of the marker comment lines excluding their indentation.
+	// The markers exceeding the width are wrapped with the backslash continuations.
+	// The markers are not wrapped if it is zero.
+	Width int
+}
+
+// FormatSource formats the marker comments in the given Go source and returns the formatted source.
+// The markers are parsed by using their definitions in the registry and written in the canonical form:
+// the value syntax is used if the value argument exists, the arguments are written in a stable order
+// and the strings are only quoted where needed. The other comments are preserved, and so are
+// the markers which cannot be resolved or parsed.
+func FormatSource(registry *Registry, src []byte, options FormatOptions) ([]byte, error) {
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, "", src, parser.ParseComments)
+
+	if err != nil {
+		return nil, err
+	}
+
+	markerComments := newCommentVisitor(file.Comments).getMarkerComments(0, len(file.Comments))
+	importAliases := fileImportAliases(registry, markerComments)
+
+	return rewriteMarkerComments(fileSet, src, markerComments, options.Width, func(comment markerComment, width int) []string {
+		lines, _ := formatMarkerComment(registry, importAliases, comment.Text(), width)
+		return lines
+	}), nil
+}
+
+// rewriteMarkerComments replaces the given marker comments in the source with the marker lines
+// returned by the rewrite function. The comments for which the function returns nil are kept.
+func rewriteMarkerComments(fileSet *token.FileSet, src []byte, markerComments []markerComment, width int,
+	rewrite func(comment markerComment, width int) []string) []byte {
+	var result bytes.Buffer
+	lastOffset := 0
+
+	for _, comment := range markerComments {
+		startOffset := fileSet.Position(comment.Pos()).Offset
+		endOffset := fileSet.Position(comment.End()).Offset
+		lineStart := bytes.LastIndexByte(src[:startOffset], '\n') + 1
+		indent := string(src[lineStart:startOffset])
+		lineWidth := width
+
+		// the trailing comments cannot be wrapped
+		if strings.TrimSpace(indent) != "" {
+			lineWidth = 0
+			indent = ""
+		}
+
+		lines := rewrite(comment, lineWidth)
+
+		if lines == nil {
+			continue
+		}
+
+		result.Write(src[lastOffset:startOffset])
+		result.WriteString("// " + strings.Join(lines, "\n"+indent+"// "))
+		lastOffset = endOffset
+	}
+
+	result.Write(src[lastOffset:])
+	return result.Bytes()
+}
+
+// fileImportAliases returns the import markers in the given marker comments by their aliases.
+func fileImportAliases(registry *Registry, markerComments []markerComment) AliasMap {
+	aliases := make(AliasMap)
+	importDefinition, exists := registry.Lookup(ImportMarkerName, "", PackageLevel)
+
+	if !exists {
+		return aliases
+	}
+
+	for _, comment := range markerComments {
+		text := comment.Text()
+
+		if name, anonymousName, _ := splitMarker(text); name != ImportMarkerName || anonymousName != ImportMarkerName {
+			continue
+		}
+
+		value, err := importDefinition.Parse(text)
+		importMarker, isImportMarker := value.(ImportMarker)
+
+		if err != nil || !isImportMarker {
+			continue
+		}
+
+		if importMarker.Alias == "" {
+			aliases[importMarker.Value] = importMarker
+		} else {
+			aliases[importMarker.Alias] = importMarker
+		}
+	}
+
+	return aliases
+}
+
+// lookupMarkerDefinition returns the definition of the given marker text resolving the aliases of the imported
+// processors. The marker text is returned with the processor name instead of the alias, along with the marker name
+// as it is written.
+func lookupMarkerDefinition(registry *Registry, importAliases AliasMap, text string) (*Definition, string, string, bool) {
+	name, anonymousName, _ := splitMarker(text)
+	alias := strings.SplitN(name, ":", 2)[0]
+	collector := NewCollector(registry)
+
+	// the target levels are not known without the nodes, any level matches
+	anyLevel := TargetLevel(0)
+
+	if importMarker, ok := importAliases[alias]; ok {
+		text = "+" + importMarker.Value + text[len(alias)+1:]
+		name, anonymousName, _ = splitMarker(text)
+		definition, exists := collector.lookupDefinition(name, anonymousName, importMarker.Pkg, anyLevel)
+
+		if !exists {
+			definition, exists = collector.lookupDefinition(name, anonymousName, importMarker.PkgPath(), anyLevel)
+		}
+
+		if !exists {
+			return nil, "", "", false
+		}
+
+		return definition, text, alias + strings.TrimPrefix(definition.Name, importMarker.Value), true
+	} else if !IsReservedMarker(name) && !IsReservedMarker(anonymousName) {
+		return nil, "", "", false
+	}
+
+	definition, exists := collector.lookupDefinition(name, anonymousName, "", anyLevel)
+
+	if !exists {
+		return nil, "", "", false
+	}
+
+	return definition, text, definition.Name, true
+}
+
+// formatMarkerComment returns the lines of the formatted marker comment. It returns nil if the marker
+// is not formatted such as the syntax-free markers, and an error if it cannot be resolved or parsed.
+func formatMarkerComment(registry *Registry, importAliases AliasMap, text string, width int) ([]string, error) {
+	definition, resolvedText, name, exists := lookupMarkerDefinition(registry, importAliases, text)
+
+	if !exists {
+		return nil, ImportError{Marker: text}
+	}
+
+	value, seen, err := definition.parse(resolvedText)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if definition.Output.SyntaxFree || definition.AllowUnknownArguments {
+		return nil, nil
+	}
+
+	arguments, err := definition.formatArguments(value, seen)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapMarker(name, arguments, width), nil
+}
+
+// Format returns the marker text of the given output value. The text is parsed into an equal value
+// by Parse. The optional arguments are omitted if they have the value which they would get when
+// they are not written.
+func (definition *Definition) Format(value any) (string, error) {
+	if value == nil {
+		return "", errors.New("nil value cannot be formatted")
+	}
+
+	if definition.Output.SyntaxFree {
+		return definition.formatSyntaxFree(value)
+	}
+
+	names, err := definition.writtenArguments(value)
+
+	if err != nil {
+		return "", err
+	}
+
+	var arguments []string
+	arguments, err = definition.formatArguments(value, names)
+
+	if err != nil {
+		return "", err
+	}
+
+	return wrapMarker(definition.Name, arguments, 0)[0], nil
+}
+
+// writtenArguments returns the names of the arguments which are written for the given output value.
+func (definition *Definition) writtenArguments(value any) (map[string]struct{}, error) {
+	names := make(map[string]struct{})
+
+	if definition.Output.IsDynamic {
+		dynamicValue, isDynamic := value.(*DynamicValue)
+
+		if !isDynamic {
+			return nil, fmt.Errorf("value of type %T cannot be formatted as marker '%s'", value, definition.Name)
+		}
+
+		for _, argument := range dynamicValue.Arguments() {
+			if _, exists := definition.Output.Fields[argument.Name]; exists && !argument.IsDefault {
+				names[argument.Name] = struct{}{}
+			}
+		}
+
+		return names, nil
+	}
+
+	outputValue := reflect.Indirect(reflect.ValueOf(value))
+
+	if outputValue.Type() != definition.Output.Type {
+		return nil, fmt.Errorf("value of type %T cannot be formatted as marker '%s'", value, definition.Name)
+	}
+
+	if definition.Output.IsAnonymous {
+		if !outputValue.IsZero() {
+			names[ValueArgument] = struct{}{}
+		}
+
+		return names, nil
+	}
+
+	for name, argument := range definition.Output.Fields {
+		fieldValue := outputValue.FieldByName(definition.Output.FieldNames[name])
+
+		if argument.Required || !isOmittedValue(argument, fieldValue) {
+			names[name] = struct{}{}
+		}
+	}
+
+	return names, nil
+}
+
+// formatSyntaxFree returns the marker text of the given syntax-free output value.
+// The value is written as it is after the marker name.
+func (definition *Definition) formatSyntaxFree(value any) (string, error) {
+	var argumentValue any
+
+	if dynamicValue, isDynamic := value.(*DynamicValue); isDynamic {
+		argument, exists := dynamicValue.Argument(ValueArgument)
+
+		if !exists || argument.IsDefault {
+			return "+" + definition.Name, nil
+		}
+
+		argumentValue = argument.Value
+	} else {
+		outputValue := reflect.Indirect(reflect.ValueOf(value))
+
+		if outputValue.Type() != definition.Output.Type {
+			return "", fmt.Errorf("value of type %T cannot be formatted as marker '%s'", value, definition.Name)
+		}
+
+		fieldName, exists := definition.Output.FieldNames[ValueArgument]
+
+		if !exists {
+			return "+" + definition.Name, nil
+		}
+
+		argumentValue = reflect.Indirect(outputValue.FieldByName(fieldName)).Interface()
+	}
+
+	text, isText := argumentValue.(string)
+
+	if !isText {
+		return "", fmt.Errorf("value of type %T cannot be formatted as marker '%s'", argumentValue, definition.Name)
+	}
+
+	if text == "" {
+		if argument := definition.Output.Fields[ValueArgument]; argument.Default != nil && argument.Default != "" {
+			return "", fmt.Errorf("empty value cannot be formatted as marker '%s' since its default value is used", definition.Name)
+		}
+
+		return "+" + definition.Name, nil
+	}
+
+	if text != strings.TrimSpace(text) || strings.ContainsAny(text, "\r\n") {
+		return "", fmt.Errorf("value %q cannot be formatted as marker '%s'", text, definition.Name)
+	}
+
+	return "+" + definition.Name + " " + text, nil
+}
+
+// formatArguments returns the written arguments of the given value in the order they are written.
+// The value argument is written without its name.
+func (definition *Definition) formatArguments(value any, names map[string]struct{}) ([]string, error) {
+	arguments := make([]string, 0, len(names))
+	outputValue := reflect.Indirect(reflect.ValueOf(value))
+
+	if definition.Output.IsAnonymous {
+		if _, written := names[ValueArgument]; !written {
+			return arguments, nil
+		}
+
+		text, err := formatValue(definition.Output.AnonymousTypeInfo, outputValue)
+
+		if err != nil {
+			return nil, fmt.Errorf("argument '%s' cannot be formatted: %w", ValueArgument, err)
+		}
+
+		return append(arguments, "="+text), nil
+	}
+
+	for _, name := range orderedArgumentNames(definition.Output.Fields) {
+		if _, written := names[name]; !written {
+			continue
+		}
+
+		var fieldValue reflect.Value
+
+		if dynamicValue, isDynamic := value.(*DynamicValue); isDynamic {
+			argumentValue, _ := dynamicValue.Get(name)
+			fieldValue = reflect.ValueOf(argumentValue)
+		} else {
+			fieldValue = outputValue.FieldByName(definition.Output.FieldNames[name])
+		}
+
+		text, err := formatValue(definition.Output.Fields[name].TypeInfo, fieldValue)
+
+		if err != nil {
+			return nil, fmt.Errorf("argument '%s' cannot be formatted: %w", name, err)
+		}
+
+		if name == ValueArgument {
+			arguments = append(arguments, "="+text)
+		} else {
+			arguments = append(arguments, name+"="+text)
+		}
+	}
+
+	return arguments, nil
+}
+
+// wrapMarker returns the lines of the marker with the given name and arguments. The lines
+// exceeding the width when they are written as comments are wrapped after the commas.
+func wrapMarker(name string, arguments []string, width int) []string {
+	current := "+" + name
+
+	if len(arguments) == 0 {
+		return []string{current}
+	}
+
+	if strings.HasPrefix(arguments[0], "=") {
+		current += arguments[0]
+	} else {
+		current += ":" + arguments[0]
+	}
+
+	lines := make([]string, 0, 1)
+
+	for _, argument := range arguments[1:] {
+		if width > 0 && len("// "+current+", "+argument+", \\") > width {
+			lines = append(lines, current+", \\")
+			current = argument
+			continue
+		}
+
+		current += ", " + argument
+	}
+
+	return append(lines, current)
+}
+
+// formatValue returns the given value of the given type as it is written in the marker comments.
+func formatValue(typeInfo ArgumentTypeInfo, value reflect.Value) (string, error) {
+	if !value.IsValid() {
+		return "", errors.New("value is not valid")
+	}
+
+	if value.Kind() == reflect.Interface || value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return "", errors.New("nil value cannot be formatted")
+		}
+
+		return formatValue(typeInfo, value.Elem())
+	}
+
+	switch typeInfo.ActualType {
+	case AnyType:
+		return formatAnyValue(value)
+	case BoolType:
+		return strconv.FormatBool(value.Bool()), nil
+	case SignedIntegerType:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case UnsignedIntegerType:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case FloatType:
+		return formatFloat(value.Float()), nil
+	case StringType:
+		return formatString(typeInfo.enumKey(value.String()), false), nil
+	case DurationType:
+		return time.Duration(value.Int()).String(), nil
+	case TimeType:
+		return value.Interface().(time.Time).Format(time.RFC3339Nano), nil
+	case GoType, GoFuncType:
+		return fmt.Sprint(value.Interface()), nil
+	case SliceType:
+		items := make([]string, 0, value.Len())
+
+		for index := 0; index < value.Len(); index++ {
+			item, err := formatValue(*typeInfo.ItemType, value.Index(index))
+
+			if err != nil {
+				return "", err
+			}
+
+			items = append(items, item)
+		}
+
+		return "{" + strings.Join(items, ", ") + "}", nil
+	case MapType:
+		keys := make([]string, 0, value.Len())
+
+		for _, key := range value.MapKeys() {
+			keys = append(keys, key.String())
+		}
+
+		sort.Strings(keys)
+		items := make([]string, 0, len(keys))
+
+		for _, key := range keys {
+			item, err := formatValue(*typeInfo.ItemType, value.MapIndex(reflect.ValueOf(key).Convert(value.Type().Key())))
+
+			if err != nil {
+				return "", err
+			}
+
+			items = append(items, formatString(key, false)+": "+item)
+		}
+
+		return "{" + strings.Join(items, ", ") + "}", nil
+	case StructType:
+		return formatStruct(typeInfo, value)
+	}
+
+	return "", fmt.Errorf("type %v cannot be formatted", typeInfo.ActualType)
+}
+
+// formatStruct returns the fields of the given struct value. The optional fields are omitted
+// if they have the value which they would get when they are not written.
+func formatStruct(typeInfo ArgumentTypeInfo, value reflect.Value) (string, error) {
+	fields := make([]string, 0, len(typeInfo.Fields))
+
+	for _, name := range sortedArgumentNames(typeInfo.Fields) {
+		field := typeInfo.Fields[name]
+		fieldValue := value.FieldByName(typeInfo.FieldNames[name])
+
+		if !field.Required && isOmittedValue(field, fieldValue) {
+			continue
+		}
+
+		text, err := formatValue(field.TypeInfo, fieldValue)
+
+		if err != nil {
+			return "", err
+		}
+
+		fields = append(fields, name+": "+text)
+	}
+
+	return "{" + strings.Join(fields, ", ") + "}", nil
+}
+
+// isOmittedValue reports whether the argument gets the given value when it is not written.
+func isOmittedValue(argument Argument, value reflect.Value) bool {
+	if argument.Default != nil {
+		return reflect.DeepEqual(value.Interface(), argument.Default)
+	}
+
+	return value.IsZero()
+}
+
+// formatAnyValue returns the given value whose type is inferred while parsing.
+func formatAnyValue(value reflect.Value) (string, error) {
+	switch value.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Int64:
+		if value.Type() == durationType {
+			return time.Duration(value.Int()).String(), nil
+		}
+
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return formatFloat(value.Float()), nil
+	case reflect.String:
+		return formatString(value.String(), true), nil
+	case reflect.Slice:
+		return formatValue(ArgumentTypeInfo{ActualType: SliceType, ItemType: &ArgumentTypeInfo{ActualType: AnyType}}, value)
+	case reflect.Map:
+		return formatValue(ArgumentTypeInfo{ActualType: MapType, ItemType: &ArgumentTypeInfo{ActualType: AnyType}}, value)
+	case reflect.Struct:
+		if value.Type() == timeType {
+			return value.Interface().(time.Time).Format(time.RFC3339Nano), nil
+		}
+	}
+
+	return "", fmt.Errorf("type %v cannot be formatted", value.Type())
+}
+
+// formatFloat returns the given float with a decimal point so that it is not inferred as an integer.
+func formatFloat(value float64) string {
+	text := strconv.FormatFloat(value, 'f', -1, 64)
+
+	if !strings.ContainsAny(text, ".eEnN") {
+		text += ".0"
+	}
+
+	return text
+}
+
+// formatString returns the given string quoted if it cannot be written as it is. The strings
+// whose types are inferred are also quoted if they would be inferred as another type.
+func formatString(value string, isInferred bool) string {
+	if value == "" || strings.ContainsAny(value, " \t,;:{}=\"'`\\\n") || strings.IndexFunc(value, isNotPrintable) != -1 {
+		return strconv.Quote(value)
+	}
+
+	if !isInferred {
+		return value
+	}
+
+	first := value[0]
+
+	if value == "true" || value == "false" || first == '-' || first >= '0' && first <= '9' {
+		return strconv.Quote(value)
+	}
+
+	return value
+}
+
+func isNotPrintable(character rune) bool {
+	return !strconv.IsPrint(character)
+}
+
+// enumKey returns the enum key which is mapped to the given value.
+func (typeInfo ArgumentTypeInfo) enumKey(value string) string {
+	if len(typeInfo.Enum) == 0 {
+		return value
+	}
+
+	if enumValue, exists := typeInfo.Enum[value]; exists && enumValue == value {
+		return value
+	}
+
+	keys := make([]string, 0, len(typeInfo.Enum))
+
+	for key := range typeInfo.Enum {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if typeInfo.Enum[key] == value {
+			return key
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,336 @@
+package markers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+const formatSource = `// +import=test,Pkg=anyPkg
+package demo
+
+// Users is a bean.
+// +test:bean:Scope = prototype,Name=users,Labels={b:true, a:false}
+type Users struct {
+	// +test:bean:Name = "a:b", Timeout=90s, Column={Name: id, Order: desc}, \
+	// Since=2022-03-04T10:20:30Z
+	Field string // +test:bean:Ratio=2,Name=field
+}
+
+// +test:bean:Name=x, Unknown=1
+// +test:unknown:Name=x
+// +deprecated  use Orders instead
+type Orders struct{}
+`
+
+func newFormatTestRegistry(t *testing.T) *Registry {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register("test:bean", "anyPkg", AllLevels, &schemaMarker{}))
+	return registry
+}
+
+func TestFormatSource(t *testing.T) {
+	formatted, err := FormatSource(newFormatTestRegistry(t), []byte(formatSource), FormatOptions{Width: DefaultFormatWidth})
+	assert.NoError(t, err)
+	assert.Equal(t, `// +import=test, Pkg=anyPkg
+package demo
+
+// Users is a bean.
+// +test:bean:Name=users, Labels={a: false, b: true}, Scope=prototype
+type Users struct {
+	// +test:bean:Name="a:b", Column={Name: id, Order: desc}, Since=2022-03-04T10:20:30Z, \
+	// Timeout=1m30s
+	Field string // +test:bean:Name=field, Ratio=2.0
+}
+
+// +test:bean:Name=x, Unknown=1
+// +test:unknown:Name=x
+// +deprecated  use Orders instead
+type Orders struct{}
+`, string(formatted))
+
+	again, err := FormatSource(newFormatTestRegistry(t), formatted, FormatOptions{Width: DefaultFormatWidth})
+	assert.NoError(t, err)
+	assert.Equal(t, string(formatted), string(again))
+}
+
+func TestFormatSource_Wrap(t *testing.T) {
+	source := "package demo\n\n" +
+		"type Users struct {\n" +
+		"\t// +import=test, Pkg=anyPkg\n" +
+		"\t// +test:bean:Name=users, Scope=prototype, Priority=3, Headers={Accept: json}\n" +
+		"\tField string\n" +
+		"}\n"
+
+	formatted, err := FormatSource(newFormatTestRegistry(t), []byte(source), FormatOptions{Width: 40})
+	assert.NoError(t, err)
+	assert.Equal(t, "package demo\n\n"+
+		"type Users struct {\n"+
+		"\t// +import=test, Pkg=anyPkg\n"+
+		"\t// +test:bean:Name=users, \\\n"+
+		"\t// Headers={Accept: json}, Priority=3, \\\n"+
+		"\t// Scope=prototype\n"+
+		"\tField string\n"+
+		"}\n", string(formatted))
+
+	formatted, err = FormatSource(newFormatTestRegistry(t), formatted, FormatOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, string(formatted), "\t// +test:bean:Name=users, Headers={Accept: json}, Priority=3, Scope=prototype\n")
+
+	_, err = FormatSource(newFormatTestRegistry(t), []byte("package"), FormatOptions{})
+	assert.Error(t, err)
+}
+
+func TestFormatValue(t *testing.T) {
+	size := 3
+	stringSliceType, _ := ArgumentTypeInfoFromType(reflect.TypeOf([]string{}))
+	columnType, _ := ArgumentTypeInfoFromType(reflect.TypeOf(indexColumn{}))
+
+	testCases := []struct {
+		TypeInfo ArgumentTypeInfo
+		Value    any
+		Expected string
+	}{
+		{TypeInfo: ArgumentTypeInfo{ActualType: StringType}, Value: "", Expected: `""`},
+		{TypeInfo: ArgumentTypeInfo{ActualType: StringType}, Value: "github.com/a/b", Expected: "github.com/a/b"},
+		{TypeInfo: ArgumentTypeInfo{ActualType: StringType}, Value: "a, b", Expected: `"a, b"`},
+		{TypeInfo: ArgumentTypeInfo{ActualType: StringType}, Value: " a", Expected: `" a"`},
+		{TypeInfo: ArgumentTypeInfo{ActualType: StringType, Enum: map[string]any{"asc": "ASCENDING"}}, Value: "ASCENDING", Expected: "asc"},
+		{TypeInfo: ArgumentTypeInfo{ActualType: FloatType}, Value: 2.5, Expected: "2.5"},
+		{TypeInfo: ArgumentTypeInfo{ActualType: UnsignedIntegerType}, Value: uint(7), Expected: "7"},
+		{TypeInfo: ArgumentTypeInfo{ActualType: DurationType}, Value: 2 * time.Second, Expected: "2s"},
+		{TypeInfo: ArgumentTypeInfo{ActualType: GoType}, Value: TypeReference{Package: "http", Name: "Handler"}, Expected: "http.Handler"},
+		{TypeInfo: stringSliceType, Value: []string{"a", "b:c"}, Expected: `{a, "b:c"}`},
+		{TypeInfo: stringSliceType, Value: []string{}, Expected: "{}"},
+		{TypeInfo: columnType, Value: indexColumn{Name: "id", Order: "asc", Size: &size}, Expected: "{Name: id, Size: 3}"},
+		{TypeInfo: ArgumentTypeInfo{ActualType: AnyType}, Value: []any{"a", "true", "12", 1, 1.0, true}, Expected: `{a, "true", "12", 1, 1.0, true}`},
+		{TypeInfo: ArgumentTypeInfo{ActualType: AnyType}, Value: map[string]any{"b": "x", "a": time.Minute}, Expected: "{a: 1m0s, b: x}"},
+	}
+
+	for _, testCase := range testCases {
+		text, err := formatValue(testCase.TypeInfo, reflect.ValueOf(testCase.Value))
+		assert.NoError(t, err, testCase.Expected)
+		assert.Equal(t, testCase.Expected, text)
+	}
+
+	_, err := formatValue(ArgumentTypeInfo{ActualType: AnyType}, reflect.ValueOf(struct{}{}))
+	assert.Error(t, err)
+}
+
+type roundTripMarker struct {
+	Value   string         `parameter:"Value"`
+	Count   int            `parameter:"Count" required:"true"`
+	Size    uint16         `parameter:"Size"`
+	Ratio   float64        `parameter:"Ratio"`
+	Enabled bool           `parameter:"Enabled" default:"true"`
+	Timeout time.Duration  `parameter:"Timeout" default:"5s"`
+	Since   time.Time      `parameter:"Since"`
+	Order   string         `parameter:"Order" enum:"asc,desc" default:"asc"`
+	Tags    []string       `parameter:"Tags"`
+	Limits  map[string]int `parameter:"Limits"`
+	Columns []indexColumn  `parameter:"Columns"`
+	Any     any            `parameter:"Any"`
+}
+
+func (roundTripMarker) Generate(random *rand.Rand, size int) reflect.Value {
+	marker := roundTripMarker{
+		Value:   randomString(random),
+		Count:   random.Int() - random.Int(),
+		Size:    uint16(random.Intn(1 << 16)),
+		Ratio:   random.NormFloat64() * 1e6,
+		Enabled: random.Intn(2) == 0,
+		Timeout: time.Duration(random.Int63n(int64(time.Hour))),
+		Order:   []string{"asc", "desc"}[random.Intn(2)],
+	}
+
+	if random.Intn(2) == 0 {
+		marker.Since = time.Unix(random.Int63n(1<<32), random.Int63n(int64(time.Second))).UTC()
+	}
+
+	if count := random.Intn(size + 2); count != 0 {
+		marker.Tags = make([]string, count-1)
+
+		for index := range marker.Tags {
+			marker.Tags[index] = randomString(random)
+		}
+	}
+
+	if count := random.Intn(size + 2); count != 0 {
+		marker.Limits = make(map[string]int)
+
+		for index := 0; index < count-1; index++ {
+			marker.Limits[randomString(random)] = random.Int() - random.Int()
+		}
+	}
+
+	for index := random.Intn(3); index > 0; index-- {
+		column := indexColumn{Name: randomString(random), Order: "asc"}
+
+		if random.Intn(2) == 0 {
+			column.Order = "desc"
+		}
+
+		if random.Intn(2) == 0 {
+			columnSize := random.Intn(100)
+			column.Size = &columnSize
+		}
+
+		marker.Columns = append(marker.Columns, column)
+	}
+
+	switch random.Intn(5) {
+	case 0:
+		marker.Any = randomString(random)
+	case 1:
+		marker.Any = random.Intn(1000) - 500
+	case 2:
+		marker.Any = random.Intn(2) == 0
+	case 3:
+		marker.Any = float64(random.Intn(1000)) / 8
+	}
+
+	return reflect.ValueOf(marker)
+}
+
+// randomString returns a string which is likely to contain the characters having meaning in the markers.
+func randomString(random *rand.Rand) string {
+	characters := []rune("ab Z09-_.,;:{}=\"'`\\/\tçö界+")
+	words := []string{"", "true", "false", "12", "-3", "1.5", "2s"}
+
+	if random.Intn(4) == 0 {
+		return words[random.Intn(len(words))]
+	}
+
+	text := make([]rune, random.Intn(12))
+
+	for index := range text {
+		text[index] = characters[random.Intn(len(characters))]
+	}
+
+	return string(text)
+}
+
+func TestDefinition_FormatShouldRoundTrip(t *testing.T) {
+	definition, err := MakeDefinition("test:round-trip", "anyPkg", AllLevels, &roundTripMarker{})
+	assert.NoError(t, err)
+
+	err = quick.Check(func(marker roundTripMarker) bool {
+		text, err := definition.Format(marker)
+
+		if !assert.NoError(t, err) {
+			return false
+		}
+
+		value, err := definition.Parse(text)
+		return assert.NoError(t, err, text) && assert.Equal(t, marker, value, text)
+	}, &quick.Config{MaxCount: 500})
+	assert.NoError(t, err)
+}
+
+func TestDefinition_FormatShouldRoundTripAnonymousOutputs(t *testing.T) {
+	outputs := []any{
+		[]string{},
+		map[string]int{},
+		int64(0),
+		float64(0),
+		"",
+		true,
+		time.Duration(0),
+		map[string][]bool{},
+	}
+
+	for _, output := range outputs {
+		outputType := reflect.TypeOf(output)
+		definition, err := MakeDefinition("test:anonymous", "anyPkg", AllLevels, output)
+		assert.NoError(t, err)
+
+		roundTrip := reflect.MakeFunc(reflect.FuncOf([]reflect.Type{outputType}, []reflect.Type{reflect.TypeOf(true)}, false),
+			func(args []reflect.Value) []reflect.Value {
+				text, err := definition.Format(args[0].Interface())
+				result := assert.NoError(t, err)
+
+				if result {
+					var value any
+					value, err = definition.Parse(text)
+					result = assert.NoError(t, err, text) && assert.Equal(t, args[0].Interface(), value, text)
+				}
+
+				return []reflect.Value{reflect.ValueOf(result)}
+			})
+
+		assert.NoError(t, quick.Check(roundTrip.Interface(), &quick.Config{MaxCount: 200}), outputType.String())
+	}
+}
+
+func TestDefinition_FormatShouldRoundTripSyntaxFreeOutputs(t *testing.T) {
+	definition := NewRegistry().packageMap[""][DeprecatedMarkerName]
+
+	err := quick.Check(func(message string) bool {
+		text, err := definition.Format(DeprecatedMarker{Value: message})
+
+		if message != strings.TrimSpace(message) || strings.ContainsAny(message, "\r\n") {
+			return assert.Error(t, err)
+		}
+
+		if !assert.NoError(t, err) {
+			return false
+		}
+
+		value, err := definition.Parse(text)
+		return assert.NoError(t, err) && assert.Equal(t, DeprecatedMarker{Value: message}, value)
+	}, nil)
+	assert.NoError(t, err)
+}
+
+func TestDefinition_Format(t *testing.T) {
+	definition, err := MakeDefinition("test:bean", "anyPkg", AllLevels, &schemaMarker{})
+	assert.NoError(t, err)
+
+	text, err := definition.Format(&schemaMarker{Name: "users", Timeout: 5 * time.Second, Type: TypeReference{Package: "http", Name: "Handler"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "+test:bean:Name=users, Scope=\"\", Type=http.Handler", text)
+
+	_, err = definition.Format(indexColumn{})
+	assert.EqualError(t, err, "value of type markers.indexColumn cannot be formatted as marker 'test:bean'")
+
+	_, err = definition.Format(nil)
+	assert.Error(t, err)
+
+	anonymous, err := MakeDefinition("test:names", "anyPkg", AllLevels, []string{})
+	assert.NoError(t, err)
+
+	text, err = anonymous.Format([]string{"a", "b c"})
+	assert.NoError(t, err)
+	assert.Equal(t, `+test:names={a, "b c"}`, text)
+
+	text, err = anonymous.Format([]string(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "+test:names", text)
+
+	_, err = anonymous.Parse("+test:names={a}, b")
+	assert.Error(t, err)
+
+	dynamic, err := MakeDynamicDefinition("test:cache", "anyPkg", FunctionLevel,
+		Argument{Name: "Name", TypeInfo: ArgumentTypeInfo{ActualType: StringType}, Required: true},
+		Argument{Name: "Tags", TypeInfo: ArgumentTypeInfo{ActualType: SliceType, ItemType: &ArgumentTypeInfo{ActualType: StringType}}, Default: []string{"a"}},
+	)
+	assert.NoError(t, err)
+
+	value, err := dynamic.Parse("+test:cache:Tags={b}, Name=users")
+	assert.NoError(t, err)
+
+	text, err = dynamic.Format(value)
+	assert.NoError(t, err)
+	assert.Equal(t, "+test:cache:Name=users, Tags={b}", text)
+
+	value, err = dynamic.Parse("+test:cache:Name=users")
+	assert.NoError(t, err)
+
+	text, err = dynamic.Format(value)
+	assert.NoError(t, err)
+	assert.Equal(t, "+test:cache:Name=users", text)
+}
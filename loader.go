@@ -0,0 +1,335 @@
+package markers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/procyon-projects/marker/packages"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// LoadDefinitions synthesizes the marker definitions declared in the source of the given package.
+// The structs annotated with +marker are turned into definitions whose arguments are the exported
+// fields of the structs. The arguments are described by +marker:parameter and +marker:enum markers,
+// the value of an enum marker is accepted in the marker text and it is replaced with its name.
+func LoadDefinitions(pkg *packages.Package) ([]*Definition, error) {
+	if pkg == nil {
+		return nil, errors.New("pkg(package) cannot be nil")
+	}
+
+	if pkg.TypesInfo == nil {
+		return nil, errors.New("type information of the package is not available")
+	}
+
+	nodeMarkers, err := NewCollector(NewRegistry()).Collect(pkg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	definitions := make([]*Definition, 0)
+
+	for _, file := range pkg.Syntax {
+		for _, declaration := range file.Decls {
+			genDecl, ok := declaration.(*ast.GenDecl)
+
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				definitionMarker, ok := nodeMarkers[typeSpec].First(DefinitionMarkerName).(DefinitionMarker)
+
+				if !ok {
+					continue
+				}
+
+				definition, err := loadDefinition(pkg, typeSpec, definitionMarker, nodeMarkers)
+
+				if err != nil {
+					position := pkg.Fset.Position(typeSpec.Pos())
+					errs = append(errs, toParseError(err, typeSpec, position))
+					continue
+				}
+
+				definitions = append(definitions, definition)
+			}
+		}
+	}
+
+	if len(errs) != 0 {
+		return nil, NewErrorList(errs)
+	}
+
+	return definitions, nil
+}
+
+func loadDefinition(pkg *packages.Package, typeSpec *ast.TypeSpec, definitionMarker DefinitionMarker, nodeMarkers map[ast.Node]MarkerValues) (*Definition, error) {
+	structType, ok := typeSpec.Type.(*ast.StructType)
+
+	if !ok {
+		return nil, fmt.Errorf("the marker '%s' can only be declared on struct types", DefinitionMarkerName)
+	}
+
+	targetLevel, err := TargetLevelFromNames(definitionMarker.Targets)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]reflect.StructField, 0)
+	defaultValues := make(map[string]any)
+	argumentFields := make(map[string]string)
+
+	for _, field := range structType.Fields.List {
+		if len(field.Names) == 0 {
+			return nil, fmt.Errorf("embedded field '%s' cannot be an argument", types.ExprString(field.Type))
+		}
+
+		for _, fieldName := range field.Names {
+			if !fieldName.IsExported() {
+				continue
+			}
+
+			object := pkg.TypesInfo.Defs[fieldName]
+
+			if object == nil {
+				return nil, fmt.Errorf("type of field '%s' cannot be resolved", fieldName.Name)
+			}
+
+			fieldType, err := reflectTypeOf(object.Type(), make(map[*types.Named]bool))
+
+			if err != nil {
+				return nil, fmt.Errorf("field '%s' cannot be an argument: %w", fieldName.Name, err)
+			}
+
+			argumentName, tag := argumentTag(fieldName.Name, field, nodeMarkers[field])
+
+			if previousFieldName, ok := argumentFields[argumentName]; ok {
+				return nil, fmt.Errorf("fields '%s' and '%s' cannot have the same argument name '%s'", previousFieldName, fieldName.Name, argumentName)
+			}
+
+			argumentFields[argumentName] = fieldName.Name
+
+			if parameterMarker, ok := nodeMarkers[field].First(DefinitionParameterMarkerName).(DefinitionParameterMarker); ok && parameterMarker.Default != nil {
+				defaultValues[argumentName] = parameterMarker.Default
+			}
+
+			fields = append(fields, reflect.StructField{
+				Name: fieldName.Name,
+				Type: fieldType,
+				Tag:  tag,
+			})
+		}
+	}
+
+	definition, err := MakeDefinition(definitionMarker.Value, pkg.PkgPath, targetLevel, reflect.New(reflect.StructOf(fields)).Interface())
+
+	if err != nil {
+		return nil, err
+	}
+
+	for argumentName, defaultValue := range defaultValues {
+		err = definition.SetDefaultValue(argumentName, defaultValue)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	definition.Description = definitionMarker.Description
+	definition.Repeatable = definitionMarker.Repeatable
+	definition.Output.SyntaxFree = definitionMarker.SyntaxFree
+
+	if deprecatedMarker, ok := nodeMarkers[typeSpec].First(DeprecatedMarkerName).(DeprecatedMarker); ok {
+		definition.Deprecated = true
+		definition.DeprecationMessage = deprecatedMarker.Value
+	}
+
+	return definition, nil
+}
+
+// argumentTag returns the argument name and the struct tag of the given field. The tag is
+// synthesized from the markers of the field, the existing tags are kept unless they are overridden.
+func argumentTag(fieldName string, field *ast.Field, markerValues MarkerValues) (string, reflect.StructTag) {
+	var existingTag reflect.StructTag
+
+	if field.Tag != nil {
+		tag, err := strconv.Unquote(field.Tag.Value)
+
+		if err == nil {
+			existingTag = reflect.StructTag(tag)
+		}
+	}
+
+	argumentName := UpperCamelCase(fieldName)
+
+	if parameterTag, ok := existingTag.Lookup("parameter"); ok && parameterTag != "" {
+		argumentName = parameterTag
+	}
+
+	var tags []string
+	parameterMarker, hasParameterMarker := markerValues.First(DefinitionParameterMarkerName).(DefinitionParameterMarker)
+
+	if hasParameterMarker {
+		argumentName = parameterMarker.Value
+		tags = append(tags, fmt.Sprintf("description:%s", strconv.Quote(parameterMarker.Description)))
+
+		if parameterMarker.Required {
+			tags = append(tags, `required:"true"`)
+		}
+	}
+
+	tags = append([]string{fmt.Sprintf("parameter:%s", strconv.Quote(argumentName))}, tags...)
+
+	if deprecatedMarker, ok := markerValues.First(DeprecatedMarkerName).(DeprecatedMarker); ok {
+		tags = append(tags, fmt.Sprintf("deprecated:%s", strconv.Quote(deprecatedMarker.Value)))
+	} else if hasParameterMarker && parameterMarker.Deprecated {
+		tags = append(tags, `deprecated:""`)
+	}
+
+	enumValues := make([]string, 0)
+
+	for _, value := range markerValues.AllMarkers(DefinitionEnumMarkerName) {
+		enumMarker := value.(DefinitionEnumMarker)
+		enumValues = append(enumValues, fmt.Sprintf("%s=%s", enumMarker.Value, enumMarker.Name))
+	}
+
+	if len(enumValues) != 0 {
+		tags = append(tags, fmt.Sprintf("enum:%s", strconv.Quote(strings.Join(enumValues, ","))))
+	}
+
+	if existingTag != "" {
+		tags = append(tags, string(existingTag))
+	}
+
+	return argumentName, reflect.StructTag(strings.Join(tags, " "))
+}
+
+// reflectTypeOf returns the reflect type corresponding to the given Go type.
+func reflectTypeOf(typ types.Type, visiting map[*types.Named]bool) (reflect.Type, error) {
+	switch typedType := typ.(type) {
+	case *types.Named:
+		object := typedType.Obj()
+
+		if object.Pkg() != nil {
+			switch object.Pkg().Path() + "." + object.Name() {
+			case durationType.PkgPath() + "." + durationType.Name():
+				return durationType, nil
+			case timeType.PkgPath() + "." + timeType.Name():
+				return timeType, nil
+			case typeReferenceType.PkgPath() + "." + typeReferenceType.Name():
+				return typeReferenceType, nil
+			case funcReferenceType.PkgPath() + "." + funcReferenceType.Name():
+				return funcReferenceType, nil
+			}
+		}
+
+		if visiting[typedType] {
+			return nil, fmt.Errorf("recursive type %s is not supported", typ)
+		}
+
+		visiting[typedType] = true
+		defer delete(visiting, typedType)
+
+		return reflectTypeOf(typedType.Underlying(), visiting)
+	case *types.Basic:
+		switch typedType.Kind() {
+		case types.Bool:
+			return reflect.TypeOf(false), nil
+		case types.String:
+			return reflect.TypeOf(""), nil
+		case types.Int:
+			return reflect.TypeOf(0), nil
+		case types.Int8:
+			return reflect.TypeOf(int8(0)), nil
+		case types.Int16:
+			return reflect.TypeOf(int16(0)), nil
+		case types.Int32:
+			return reflect.TypeOf(int32(0)), nil
+		case types.Int64:
+			return reflect.TypeOf(int64(0)), nil
+		case types.Uint:
+			return reflect.TypeOf(uint(0)), nil
+		case types.Uint8:
+			return reflect.TypeOf(uint8(0)), nil
+		case types.Uint16:
+			return reflect.TypeOf(uint16(0)), nil
+		case types.Uint32:
+			return reflect.TypeOf(uint32(0)), nil
+		case types.Uint64:
+			return reflect.TypeOf(uint64(0)), nil
+		case types.Float32:
+			return reflect.TypeOf(float32(0)), nil
+		case types.Float64:
+			return reflect.TypeOf(float64(0)), nil
+		}
+	case *types.Pointer:
+		elemType, err := reflectTypeOf(typedType.Elem(), visiting)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return reflect.PtrTo(elemType), nil
+	case *types.Slice:
+		elemType, err := reflectTypeOf(typedType.Elem(), visiting)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return reflect.SliceOf(elemType), nil
+	case *types.Map:
+		keyType, err := reflectTypeOf(typedType.Key(), visiting)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var elemType reflect.Type
+		elemType, err = reflectTypeOf(typedType.Elem(), visiting)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return reflect.MapOf(keyType, elemType), nil
+	case *types.Interface:
+		if typedType.Empty() {
+			return anyType, nil
+		}
+	case *types.Struct:
+		fields := make([]reflect.StructField, 0, typedType.NumFields())
+
+		for index := 0; index < typedType.NumFields(); index++ {
+			field := typedType.Field(index)
+
+			if !field.Exported() {
+				continue
+			}
+
+			fieldType, err := reflectTypeOf(field.Type(), visiting)
+
+			if err != nil {
+				return nil, err
+			}
+
+			fields = append(fields, reflect.StructField{
+				Name: field.Name(),
+				Type: fieldType,
+				Tag:  reflect.StructTag(typedType.Tag(index)),
+			})
+		}
+
+		return reflect.StructOf(fields), nil
+	}
+
+	return nil, fmt.Errorf("type %s is not supported", typ)
+}
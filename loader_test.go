@@ -0,0 +1,134 @@
+package markers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const definitionSource = `
+package source
+
+import "time"
+
+// +marker=cache, Description="caches the results", Targets={FUNCTION_LEVEL, STRUCT_METHOD_LEVEL}, Repeatable=true
+// +deprecated use memo instead
+type Cache struct {
+	// +marker:parameter=ttl, Description="time to live", Default=1m
+	Ttl time.Duration
+	// +marker:parameter=Order, Description="order of the entries", Required=true
+	// +marker:enum=asc, Name=ASC
+	// +marker:enum=desc, Name=DESC
+	Order string
+	// +marker:parameter=Tags, Description="tags of the entries", Deprecated=true
+	Tags []string
+	Index IndexOptions ` + "`parameter:\"Index\"`" + `
+	hidden bool
+}
+
+type IndexOptions struct {
+	Name string ` + "`parameter:\"Name\" required:\"true\"`" + `
+	Size *int
+}
+
+type Unrelated struct {
+	Value string
+}
+`
+
+func TestLoadDefinitions(t *testing.T) {
+	definitions, err := LoadDefinitions(newTestPackage(t, definitionSource))
+	assert.NoError(t, err)
+	assert.Len(t, definitions, 1)
+
+	definition := definitions[0]
+	assert.Equal(t, "cache", definition.Name)
+	assert.Equal(t, "caches the results", definition.Description)
+	assert.Equal(t, "github.com/procyon-projects/marker/test/source", definition.Package)
+	assert.Equal(t, FunctionLevel|StructMethodLevel, definition.TargetLevel)
+	assert.True(t, definition.Repeatable)
+	assert.True(t, definition.Deprecated)
+	assert.Equal(t, "use memo instead", definition.DeprecationMessage)
+	assert.Equal(t, []string{"Index", "Order", "Tags", "ttl"}, sortedArgumentNames(definition.Output.Fields))
+
+	ttl := definition.Output.Fields["ttl"]
+	assert.Equal(t, DurationType, ttl.TypeInfo.ActualType)
+	assert.Equal(t, "time to live", ttl.Description)
+	assert.Equal(t, time.Minute, ttl.Default)
+
+	order := definition.Output.Fields["Order"]
+	assert.True(t, order.Required)
+	assert.Equal(t, map[string]any{"asc": "ASC", "desc": "DESC"}, order.TypeInfo.Enum)
+
+	assert.True(t, definition.Output.Fields["Tags"].Deprecated)
+	assert.Equal(t, StructType, definition.Output.Fields["Index"].TypeInfo.ActualType)
+
+	value, err := definition.Parse("+cache:Order=desc, Tags={a, b}, Index={Name: idx, Size: 3}")
+	assert.NoError(t, err)
+
+	output := reflect.ValueOf(value)
+	assert.Equal(t, time.Minute, output.FieldByName("Ttl").Interface())
+	assert.Equal(t, "DESC", output.FieldByName("Order").Interface())
+	assert.Equal(t, []string{"a", "b"}, output.FieldByName("Tags").Interface())
+	assert.Equal(t, "idx", output.FieldByName("Index").FieldByName("Name").Interface())
+	assert.Equal(t, 3, output.FieldByName("Index").FieldByName("Size").Elem().Interface())
+	assert.False(t, output.FieldByName("hidden").IsValid())
+
+	_, err = definition.Parse("+cache:Tags={a}")
+	assert.Error(t, err)
+}
+
+func TestLoadDefinitions_UnsupportedField(t *testing.T) {
+	_, err := LoadDefinitions(newTestPackage(t, `
+package source
+
+// +marker=worker, Description="runs in background", Targets={FUNCTION_LEVEL}
+type Worker struct {
+	Done chan bool
+}
+`))
+
+	assert.EqualError(t, err, "[field 'Done' cannot be an argument: type chan bool is not supported]")
+	assert.Equal(t, Position{Line: 5, Column: 6}, err.(ErrorList)[0].(ParserError).Position)
+}
+
+func TestLoadDefinitions_FieldsWithSameArgumentName(t *testing.T) {
+	_, err := LoadDefinitions(newTestPackage(t, `
+package source
+
+// +marker=retry, Description="retries the calls", Targets={FUNCTION_LEVEL}
+type Retry struct {
+	// +marker:parameter=Count, Description="number of the attempts"
+	Attempts, Retries int
+}
+`))
+
+	assert.EqualError(t, err, "[fields 'Attempts' and 'Retries' cannot have the same argument name 'Count']")
+	assert.Equal(t, Position{Line: 5, Column: 6}, err.(ErrorList)[0].(ParserError).Position)
+}
+
+func TestLoadDefinitions_EmbeddedField(t *testing.T) {
+	_, err := LoadDefinitions(newTestPackage(t, `
+package source
+
+import "time"
+
+// +marker=timer, Description="times the calls", Targets={FUNCTION_LEVEL}
+type Timer struct {
+	time.Duration
+}
+`))
+
+	assert.EqualError(t, err, "[embedded field 'time.Duration' cannot be an argument]")
+	assert.Equal(t, Position{Line: 7, Column: 6}, err.(ErrorList)[0].(ParserError).Position)
+}
+
+func TestRegistry_RegisterPackage(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.RegisterPackage(newTestPackage(t, definitionSource)))
+
+	definition, exists := registry.Lookup("cache", "github.com/procyon-projects/marker/test/source", FunctionLevel)
+	assert.True(t, exists)
+	assert.Equal(t, "cache", definition.Name)
+}
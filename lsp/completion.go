@@ -0,0 +1,245 @@
+package lsp
+
+import (
+	"github.com/procyon-projects/marker"
+	"sort"
+	"strings"
+)
+
+// complete returns the completion items for the given position of the document.
+// The marker names are completed after the plus sign, the argument names after
+// the marker name and the enum values after the equal sign of an argument.
+func (server *Server) complete(doc *document, position Position) CompletionList {
+	list := CompletionList{Items: []CompletionItem{}}
+
+	line := doc.line(position.Line)
+	cursor := doc.column(position)
+	start, isMarker := markerStart(line)
+
+	if !isMarker || cursor <= start {
+		return list
+	}
+
+	text := line[start:cursor]
+	visibleMarkers := server.visibleMarkers(doc)
+	marker := findMarker(visibleMarkers, text)
+
+	if marker == nil || len(text) == len(marker.name)+1 {
+		if !strings.ContainsAny(text, "=, \t") {
+			list.Items = completeMarkerNames(doc, visibleMarkers, text, position)
+		}
+
+		return list
+	}
+
+	if marker.definition.Output.SyntaxFree {
+		return list
+	}
+
+	separator := text[len(marker.name)+1]
+	segments := splitArguments(text[len(marker.name)+2:])
+	current := segments[len(segments)-1]
+	arguments := marker.definition.Catalog().Arguments
+
+	if separator == '=' && len(segments) == 1 {
+		if argument, exists := findArgument(arguments, markers.ValueArgument); exists {
+			list.Items = completeValues(doc, argument, current, position)
+		}
+
+		return list
+	} else if separator != '=' && separator != ':' {
+		return list
+	}
+
+	usedArguments := make(map[string]struct{})
+
+	for index, segment := range segments[:len(segments)-1] {
+		if index == 0 && separator == '=' {
+			usedArguments[markers.ValueArgument] = struct{}{}
+			continue
+		}
+
+		name, _, _ := strings.Cut(segment, "=")
+		usedArguments[strings.TrimSpace(name)] = struct{}{}
+	}
+
+	name, value, hasValue := strings.Cut(strings.TrimLeft(current, " \t"), "=")
+
+	if hasValue {
+		if argument, exists := findArgument(arguments, strings.TrimSpace(name)); exists {
+			list.Items = completeValues(doc, argument, value, position)
+		}
+
+		return list
+	}
+
+	list.Items = completeArgumentNames(doc, arguments, usedArguments, name, position)
+	return list
+}
+
+// completeMarkerNames returns the names of the markers starting with the given marker text.
+func completeMarkerNames(doc *document, visibleMarkers []visibleMarker, text string, position Position) []CompletionItem {
+	items := make([]CompletionItem, 0)
+	editRange := replaceRange(doc, position, len(text)-1)
+
+	for _, marker := range visibleMarkers {
+		if !strings.HasPrefix("+"+marker.name, text) {
+			continue
+		}
+
+		items = append(items, CompletionItem{
+			Label:         marker.name,
+			Kind:          KeywordCompletion,
+			Detail:        marker.definition.Package,
+			Documentation: markdown(marker.definition.Description),
+			TextEdit: &TextEdit{
+				Range:   editRange,
+				NewText: marker.name,
+			},
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Label < items[j].Label
+	})
+
+	return items
+}
+
+// completeArgumentNames returns the names of the arguments starting with the given prefix
+// which have not been written yet.
+func completeArgumentNames(doc *document, arguments []markers.CatalogArgument, usedArguments map[string]struct{}, prefix string, position Position) []CompletionItem {
+	items := make([]CompletionItem, 0)
+	editRange := replaceRange(doc, position, len(prefix))
+
+	for _, argument := range arguments {
+		if _, used := usedArguments[argument.Name]; used || !strings.HasPrefix(argument.Name, prefix) {
+			continue
+		}
+
+		items = append(items, CompletionItem{
+			Label:         argument.Name,
+			Kind:          PropertyCompletion,
+			Detail:        argumentDetail(argument),
+			Documentation: markdown(argument.Description),
+			TextEdit: &TextEdit{
+				Range:   editRange,
+				NewText: argument.Name + "=",
+			},
+		})
+	}
+
+	return items
+}
+
+// completeValues returns the values of the given argument starting with the value written so far.
+// The items of the slices are completed separately.
+func completeValues(doc *document, argument markers.CatalogArgument, value string, position Position) []CompletionItem {
+	typ := argument.Type
+	prefix := strings.TrimLeft(value, " \t")
+
+	if typ.Kind == markers.SliceType.String() && typ.Item != nil {
+		typ = *typ.Item
+		prefix = prefix[strings.LastIndexAny(prefix, "{,")+1:]
+		prefix = strings.TrimLeft(prefix, " \t")
+	}
+
+	var values []string
+
+	if len(typ.Enum) != 0 {
+		for name := range typ.Enum {
+			values = append(values, name)
+		}
+
+		sort.Strings(values)
+	} else if typ.Kind == markers.BoolType.String() {
+		values = []string{"false", "true"}
+	}
+
+	items := make([]CompletionItem, 0)
+	editRange := replaceRange(doc, position, len(prefix))
+
+	for _, name := range values {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		kind := ValueCompletion
+
+		if len(typ.Enum) != 0 {
+			kind = EnumMemberCompletion
+		}
+
+		items = append(items, CompletionItem{
+			Label: name,
+			Kind:  kind,
+			TextEdit: &TextEdit{
+				Range:   editRange,
+				NewText: name,
+			},
+		})
+	}
+
+	return items
+}
+
+// splitArguments splits the given arguments text by the commas which are not
+// within the curly braces or the quotes.
+func splitArguments(text string) []string {
+	segments := make([]string, 0)
+	depth := 0
+	quote := rune(0)
+	start := 0
+
+	for index, r := range text {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '`':
+			quote = r
+		case r == '{':
+			depth++
+		case r == '}':
+			depth--
+		case r == ',' && depth == 0:
+			segments = append(segments, text[start:index])
+			start = index + 1
+		}
+	}
+
+	return append(segments, text[start:])
+}
+
+// findArgument returns the argument with the given name.
+func findArgument(arguments []markers.CatalogArgument, name string) (markers.CatalogArgument, bool) {
+	for _, argument := range arguments {
+		if argument.Name == name {
+			return argument, true
+		}
+	}
+
+	return markers.CatalogArgument{}, false
+}
+
+// replaceRange returns the range of the given number of bytes written before the position.
+func replaceRange(doc *document, position Position, length int) Range {
+	column := doc.column(position)
+
+	return Range{
+		Start: doc.position(position.Line, column-length),
+		End:   position,
+	}
+}
+
+func markdown(text string) *MarkupContent {
+	if text == "" {
+		return nil
+	}
+
+	return &MarkupContent{
+		Kind:  "markdown",
+		Value: text,
+	}
+}
@@ -0,0 +1,153 @@
+package lsp
+
+import (
+	"errors"
+	"github.com/procyon-projects/marker"
+	"github.com/procyon-projects/marker/packages"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	gopackages "golang.org/x/tools/go/packages"
+	"sort"
+	"strings"
+)
+
+// DiagnosticSource is the source of the diagnostics published by the server.
+const DiagnosticSource = "marker"
+
+// diagnostics parses the marker comments of the given document and returns their errors
+// and warnings. The references to the Go symbols are not resolved since the documents
+// are checked without the type information of their packages.
+func (server *Server) diagnostics(doc *document) []Diagnostic {
+	diagnostics := make([]Diagnostic, 0)
+	fileSet := token.NewFileSet()
+	file, _ := parser.ParseFile(fileSet, doc.fileName(), doc.text, parser.ParseComments)
+
+	if file == nil || file.Name == nil {
+		return diagnostics
+	}
+
+	pkg := &packages.Package{
+		Package: &gopackages.Package{
+			Name:   file.Name.Name,
+			Fset:   fileSet,
+			Syntax: []*ast.File{file},
+		},
+	}
+
+	collector := markers.NewCollector(server.registry)
+	collector.WarningCallback = func(warning markers.Warning) {
+		diagnostics = append(diagnostics, doc.diagnostic(warning.Position, SeverityWarning, warning.Message))
+	}
+
+	_, err := collector.Collect(pkg)
+
+	for _, err = range markers.FlattenErrors(err) {
+		var referenceErr markers.ReferenceError
+
+		if errors.As(err, &referenceErr) {
+			continue
+		}
+
+		var position markers.Position
+		var parserErr markers.ParserError
+
+		if errors.As(err, &parserErr) {
+			position = parserErr.Position
+		}
+
+		diagnostics = append(diagnostics, doc.diagnostic(position, SeverityError, err.Error()))
+	}
+
+	diagnostics = append(diagnostics, server.unresolvedMarkers(doc)...)
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		first, second := diagnostics[i].Range.Start, diagnostics[j].Range.Start
+
+		if first.Line != second.Line {
+			return first.Line < second.Line
+		}
+
+		return first.Character < second.Character
+	})
+
+	return diagnostics
+}
+
+// unresolvedMarkers returns the warnings for the markers which start with the alias
+// of an imported processor but do not match any of its markers.
+func (server *Server) unresolvedMarkers(doc *document) []Diagnostic {
+	diagnostics := make([]Diagnostic, 0)
+	aliases := make(map[string]struct{})
+
+	for _, importMarker := range server.importMarkers(doc) {
+		if importMarker.Alias != "" {
+			aliases[importMarker.Alias] = struct{}{}
+		} else {
+			aliases[importMarker.Value] = struct{}{}
+		}
+	}
+
+	if len(aliases) == 0 {
+		return diagnostics
+	}
+
+	visibleMarkers := server.visibleMarkers(doc)
+
+	for index := range doc.lines {
+		line := doc.line(index)
+		start, isMarker := markerStart(line)
+
+		if !isMarker {
+			continue
+		}
+
+		text := strings.TrimRight(line[start:], " \t")
+		fields := strings.FieldsFunc(text[1:], isMarkerNameSeparator)
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		alias, _, _ := strings.Cut(fields[0], ":")
+
+		if _, imported := aliases[alias]; !imported || findMarker(visibleMarkers, text) != nil {
+			continue
+		}
+
+		message := markers.ImportError{Marker: fields[0]}.Error()
+		diagnostics = append(diagnostics, doc.diagnostic(markers.Position{Line: index + 1, Column: start + 1}, SeverityWarning, message))
+	}
+
+	return diagnostics
+}
+
+func isMarkerNameSeparator(r rune) bool {
+	return r == '=' || r == ' ' || r == '\t'
+}
+
+// diagnostic returns a diagnostic starting at the given one-based line and byte column
+// and spanning the rest of the line.
+func (doc *document) diagnostic(position markers.Position, severity DiagnosticSeverity, message string) Diagnostic {
+	line := position.Line - 1
+
+	if line < 0 {
+		line = 0
+	}
+
+	column := position.Column - 1
+
+	if column < 0 {
+		column = 0
+	}
+
+	return Diagnostic{
+		Range: Range{
+			Start: doc.position(line, column),
+			End:   doc.position(line, len(doc.line(line))),
+		},
+		Severity: severity,
+		Source:   DiagnosticSource,
+		Message:  message,
+	}
+}
@@ -0,0 +1,147 @@
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// document is a text document opened in the editor.
+type document struct {
+	uri     string
+	version int
+	text    string
+	lines   []string
+}
+
+func newDocument(uri string, version int, text string) *document {
+	doc := &document{
+		uri:     uri,
+		version: version,
+	}
+	doc.setText(text)
+	return doc
+}
+
+func (doc *document) setText(text string) {
+	doc.text = text
+	doc.lines = strings.Split(text, "\n")
+}
+
+// applyChange applies the given change to the document text.
+func (doc *document) applyChange(change TextDocumentContentChangeEvent) {
+	if change.Range == nil {
+		doc.setText(change.Text)
+		return
+	}
+
+	start := doc.offset(change.Range.Start)
+	end := doc.offset(change.Range.End)
+
+	if end < start {
+		start, end = end, start
+	}
+
+	doc.setText(doc.text[:start] + change.Text + doc.text[end:])
+}
+
+// fileName returns the file name of the document.
+func (doc *document) fileName() string {
+	parsedURI, err := url.Parse(doc.uri)
+
+	if err != nil || parsedURI.Scheme != "file" {
+		return doc.uri
+	}
+
+	return filepath.FromSlash(parsedURI.Path)
+}
+
+// line returns the line with the given zero-based index without the carriage return.
+func (doc *document) line(index int) string {
+	if index < 0 || index >= len(doc.lines) {
+		return ""
+	}
+
+	return strings.TrimSuffix(doc.lines[index], "\r")
+}
+
+// column returns the byte offset within the line corresponding to the given position.
+func (doc *document) column(position Position) int {
+	return byteColumn(doc.line(position.Line), position.Character)
+}
+
+// offset returns the byte offset within the document corresponding to the given position.
+func (doc *document) offset(position Position) int {
+	if position.Line >= len(doc.lines) {
+		return len(doc.text)
+	}
+
+	offset := 0
+
+	for index := 0; index < position.Line; index++ {
+		offset += len(doc.lines[index]) + 1
+	}
+
+	return offset + byteColumn(doc.lines[position.Line], position.Character)
+}
+
+// position returns the position corresponding to the given zero-based line and byte offset within the line.
+func (doc *document) position(line, column int) Position {
+	return Position{
+		Line:      line,
+		Character: characterOffset(doc.line(line), column),
+	}
+}
+
+// byteColumn converts the given UTF-16 offset within the line into a byte offset.
+func byteColumn(line string, character int) int {
+	units := 0
+
+	for index, r := range line {
+		if units >= character {
+			return index
+		}
+
+		units += len(utf16.Encode([]rune{r}))
+	}
+
+	return len(line)
+}
+
+// characterOffset converts the given byte offset within the line into a UTF-16 offset.
+func characterOffset(line string, column int) int {
+	if column > len(line) {
+		column = len(line)
+	}
+
+	units := 0
+
+	for prefix := line[:column]; prefix != ""; {
+		r, size := utf8.DecodeRuneInString(prefix)
+		units += len(utf16.Encode([]rune{r}))
+		prefix = prefix[size:]
+	}
+
+	return units
+}
+
+// markerStart returns the byte offset of the plus sign starting the marker
+// in the given line. It returns false if the line does not contain any marker comment.
+func markerStart(line string) (int, bool) {
+	commentStart := strings.Index(line, "//")
+
+	if commentStart == -1 {
+		return 0, false
+	}
+
+	comment := line[commentStart+2:]
+	trimmed := strings.TrimLeft(comment, " \t")
+
+	if !strings.HasPrefix(trimmed, "+") {
+		return 0, false
+	}
+
+	return commentStart + 2 + len(comment) - len(trimmed), true
+}
@@ -0,0 +1,113 @@
+package lsp
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/procyon-projects/marker"
+	"strings"
+)
+
+// describe returns the documentation of the marker or the argument at the given position of the document.
+func (server *Server) describe(doc *document, position Position) *Hover {
+	line := doc.line(position.Line)
+	column := doc.column(position)
+	start, isMarker := markerStart(line)
+
+	if !isMarker || column < start {
+		return nil
+	}
+
+	marker := findMarker(server.visibleMarkers(doc), strings.TrimRight(line[start:], " \t"))
+
+	if marker == nil {
+		return nil
+	}
+
+	nameEnd := start + len(marker.name) + 1
+
+	if column <= nameEnd {
+		var buffer bytes.Buffer
+
+		if err := markers.NewMarkerDoc(marker.definition, nil).WriteMarkdown(&buffer); err != nil {
+			return nil
+		}
+
+		return &Hover{
+			Contents: MarkupContent{Kind: "markdown", Value: buffer.String()},
+			Range: &Range{
+				Start: doc.position(position.Line, start),
+				End:   doc.position(position.Line, nameEnd),
+			},
+		}
+	}
+
+	wordStart, wordEnd := wordBounds(line, column)
+
+	if wordStart == wordEnd || wordStart <= nameEnd || !strings.HasPrefix(strings.TrimLeft(line[wordEnd:], " \t"), "=") {
+		return nil
+	}
+
+	argument, exists := findArgument(marker.definition.Catalog().Arguments, line[wordStart:wordEnd])
+
+	if !exists {
+		return nil
+	}
+
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: argumentDoc(argument)},
+		Range: &Range{
+			Start: doc.position(position.Line, wordStart),
+			End:   doc.position(position.Line, wordEnd),
+		},
+	}
+}
+
+// argumentDetail returns the type of the argument and whether it is required.
+func argumentDetail(argument markers.CatalogArgument) string {
+	detail := strings.TrimSuffix(argument.Type.Kind, "Type")
+
+	if argument.Required {
+		detail += " (required)"
+	}
+
+	return detail
+}
+
+// argumentDoc returns the documentation of the argument in markdown.
+func argumentDoc(argument markers.CatalogArgument) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "**%s** `%s`", argument.Name, argumentDetail(argument))
+
+	if argument.Deprecated {
+		builder.WriteString("\n\n**Deprecated.** " + argument.DeprecationMessage)
+	}
+
+	if argument.Description != "" {
+		builder.WriteString("\n\n" + argument.Description)
+	}
+
+	if argument.Default != nil {
+		fmt.Fprintf(&builder, "\n\nDefault: `%v`", argument.Default)
+	}
+
+	return builder.String()
+}
+
+// wordBounds returns the bounds of the identifier containing the given byte offset of the line.
+func wordBounds(line string, column int) (int, int) {
+	isWordByte := func(b byte) bool {
+		return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+	}
+
+	start := column
+	for start > 0 && isWordByte(line[start-1]) {
+		start--
+	}
+
+	end := column
+	for end < len(line) && isWordByte(line[end]) {
+		end++
+	}
+
+	return start, end
+}
@@ -0,0 +1,175 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JSON-RPC error codes
+const (
+	ParseError     = -32700
+	InvalidRequest = -32600
+	MethodNotFound = -32601
+	InvalidParams  = -32602
+	InternalError  = -32603
+)
+
+// Message is a JSON-RPC 2.0 request, response or notification.
+// The notifications do not have any id.
+type Message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *ResponseError   `json:"error,omitempty"`
+}
+
+// IsRequest reports whether the message is a request which expects a response.
+func (message *Message) IsRequest() bool {
+	return message.ID != nil && message.Method != ""
+}
+
+// ResponseError is the error of a failed request.
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (err *ResponseError) Error() string {
+	return fmt.Sprintf("%s (%d)", err.Message, err.Code)
+}
+
+// Conn reads and writes the messages framed by the Content-Length headers.
+type Conn struct {
+	reader  *bufio.Reader
+	writer  io.Writer
+	writeMu sync.Mutex
+}
+
+// NewConn returns a new connection reading from the reader and writing to the writer.
+func NewConn(reader io.Reader, writer io.Writer) *Conn {
+	return &Conn{
+		reader: bufio.NewReader(reader),
+		writer: writer,
+	}
+}
+
+// Read reads the next message. It returns io.EOF if the connection is closed.
+func (conn *Conn) Read() (*Message, error) {
+	contentLength := -1
+
+	for {
+		line, err := conn.reader.ReadString('\n')
+
+		if err != nil {
+			if err == io.EOF && line == "" && contentLength == -1 {
+				return nil, io.EOF
+			}
+
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			break
+		}
+
+		name, value, found := strings.Cut(line, ":")
+
+		if !found {
+			return nil, fmt.Errorf("invalid header %q", line)
+		}
+
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			contentLength, err = strconv.Atoi(strings.TrimSpace(value))
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid content length %q", value)
+			}
+		}
+	}
+
+	if contentLength < 0 {
+		return nil, errors.New("content length is missing")
+	}
+
+	body := make([]byte, contentLength)
+	_, err := io.ReadFull(conn.reader, body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	message := &Message{}
+	err = json.Unmarshal(body, message)
+
+	if err != nil {
+		return nil, &ResponseError{Code: ParseError, Message: err.Error()}
+	}
+
+	return message, nil
+}
+
+// Write writes the given message.
+func (conn *Conn) Write(message *Message) error {
+	message.JSONRPC = "2.0"
+	body, err := json.Marshal(message)
+
+	if err != nil {
+		return err
+	}
+
+	conn.writeMu.Lock()
+	defer conn.writeMu.Unlock()
+
+	_, err = fmt.Fprintf(conn.writer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+// Request writes a request with the given id, method and params.
+func (conn *Conn) Request(id int, method string, params any) error {
+	rawID := json.RawMessage(strconv.Itoa(id))
+	return conn.send(&rawID, method, params)
+}
+
+// Notify writes a notification with the given method and params.
+func (conn *Conn) Notify(method string, params any) error {
+	return conn.send(nil, method, params)
+}
+
+func (conn *Conn) send(id *json.RawMessage, method string, params any) error {
+	rawParams, err := json.Marshal(params)
+
+	if err != nil {
+		return err
+	}
+
+	return conn.Write(&Message{
+		ID:     id,
+		Method: method,
+		Params: rawParams,
+	})
+}
+
+// Reply writes the response of the request with the given id.
+func (conn *Conn) Reply(id *json.RawMessage, result any, err *ResponseError) error {
+	if err != nil {
+		return conn.Write(&Message{ID: id, Error: err})
+	}
+
+	rawResult, marshalErr := json.Marshal(result)
+
+	if marshalErr != nil {
+		return conn.Write(&Message{ID: id, Error: &ResponseError{Code: InternalError, Message: marshalErr.Error()}})
+	}
+
+	return conn.Write(&Message{ID: id, Result: rawResult})
+}
@@ -0,0 +1,305 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/procyon-projects/marker"
+	"io"
+	"strings"
+)
+
+// ServerName is the name the server reports to the clients.
+const ServerName = "marker-lsp"
+
+// Server is a language server providing completion, hover and diagnostics
+// for the marker comments in Go files.
+type Server struct {
+	registry  *markers.Registry
+	conn      *Conn
+	documents map[string]*document
+	shutdown  bool
+}
+
+// NewServer returns a new server for the markers in the given registry.
+func NewServer(registry *markers.Registry) *Server {
+	return &Server{
+		registry:  registry,
+		documents: make(map[string]*document),
+	}
+}
+
+// Serve reads the messages from the reader and writes the responses and notifications
+// to the writer until the exit notification is received or the reader is closed.
+func (server *Server) Serve(reader io.Reader, writer io.Writer) error {
+	server.conn = NewConn(reader, writer)
+
+	for {
+		message, err := server.conn.Read()
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		var responseErr *ResponseError
+
+		if errors.As(err, &responseErr) {
+			server.conn.Reply(nil, nil, responseErr)
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if message.Method == "exit" {
+			return nil
+		}
+
+		err = server.handle(message)
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (server *Server) handle(message *Message) error {
+	if server.shutdown && message.IsRequest() {
+		return server.conn.Reply(message.ID, nil, &ResponseError{Code: InvalidRequest, Message: "server is shut down"})
+	}
+
+	var result any
+	var err error
+
+	switch message.Method {
+	case "initialize":
+		result = server.initialize()
+	case "initialized":
+	case "shutdown":
+		server.shutdown = true
+	case "textDocument/didOpen":
+		err = server.didOpen(message.Params)
+	case "textDocument/didChange":
+		err = server.didChange(message.Params)
+	case "textDocument/didClose":
+		err = server.didClose(message.Params)
+	case "textDocument/completion":
+		result, err = server.completion(message.Params)
+	case "textDocument/hover":
+		result, err = server.hover(message.Params)
+	default:
+		if message.IsRequest() {
+			return server.conn.Reply(message.ID, nil, &ResponseError{
+				Code:    MethodNotFound,
+				Message: fmt.Sprintf("method '%s' is not supported", message.Method),
+			})
+		}
+
+		return nil
+	}
+
+	if !message.IsRequest() {
+		return err
+	}
+
+	if err != nil {
+		return server.conn.Reply(message.ID, nil, &ResponseError{Code: InvalidParams, Message: err.Error()})
+	}
+
+	return server.conn.Reply(message.ID, result, nil)
+}
+
+func (server *Server) initialize() InitializeResult {
+	return InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync: TextDocumentSyncFull,
+			CompletionProvider: &CompletionOptions{
+				TriggerCharacters: []string{"+", ":", "=", ",", "{", " "},
+			},
+			HoverProvider: true,
+		},
+		ServerInfo: &ServerInfo{
+			Name: ServerName,
+		},
+	}
+}
+
+func (server *Server) didOpen(rawParams json.RawMessage) error {
+	var params DidOpenTextDocumentParams
+
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return err
+	}
+
+	doc := newDocument(params.TextDocument.URI, params.TextDocument.Version, params.TextDocument.Text)
+	server.documents[doc.uri] = doc
+	return server.publishDiagnostics(doc)
+}
+
+func (server *Server) didChange(rawParams json.RawMessage) error {
+	var params DidChangeTextDocumentParams
+
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return err
+	}
+
+	doc, exists := server.documents[params.TextDocument.URI]
+
+	if !exists {
+		return nil
+	}
+
+	for _, change := range params.ContentChanges {
+		doc.applyChange(change)
+	}
+
+	doc.version = params.TextDocument.Version
+	return server.publishDiagnostics(doc)
+}
+
+func (server *Server) didClose(rawParams json.RawMessage) error {
+	var params DidCloseTextDocumentParams
+
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return err
+	}
+
+	delete(server.documents, params.TextDocument.URI)
+
+	return server.conn.Notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         params.TextDocument.URI,
+		Diagnostics: []Diagnostic{},
+	})
+}
+
+func (server *Server) publishDiagnostics(doc *document) error {
+	return server.conn.Notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         doc.uri,
+		Diagnostics: server.diagnostics(doc),
+	})
+}
+
+func (server *Server) completion(rawParams json.RawMessage) (any, error) {
+	var params TextDocumentPositionParams
+
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return nil, err
+	}
+
+	doc, exists := server.documents[params.TextDocument.URI]
+
+	if !exists {
+		return CompletionList{Items: []CompletionItem{}}, nil
+	}
+
+	return server.complete(doc, params.Position), nil
+}
+
+func (server *Server) hover(rawParams json.RawMessage) (any, error) {
+	var params TextDocumentPositionParams
+
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return nil, err
+	}
+
+	doc, exists := server.documents[params.TextDocument.URI]
+
+	if !exists {
+		return nil, nil
+	}
+
+	hover := server.describe(doc, params.Position)
+
+	if hover == nil {
+		return nil, nil
+	}
+
+	return hover, nil
+}
+
+// visibleMarker is a marker which can be written in a document.
+type visibleMarker struct {
+	// name is the name the marker is written with, the processor
+	// name is replaced with the alias of the import marker.
+	name       string
+	definition *markers.Definition
+}
+
+// visibleMarkers returns the markers which can be used in the given document. These are
+// the reserved markers and the markers of the processors imported by the document.
+func (server *Server) visibleMarkers(doc *document) []visibleMarker {
+	visibleMarkers := make([]visibleMarker, 0)
+	definitions := server.registry.Definitions()
+
+	for _, definition := range definitions {
+		if definition.Package == "" {
+			visibleMarkers = append(visibleMarkers, visibleMarker{name: definition.Name, definition: definition})
+		}
+	}
+
+	for _, importMarker := range server.importMarkers(doc) {
+		alias := importMarker.Alias
+
+		if alias == "" {
+			alias = importMarker.Value
+		}
+
+		for _, definition := range definitions {
+			if definition.Package != importMarker.Pkg && definition.Package != importMarker.PkgPath() {
+				continue
+			}
+
+			name := definition.Name
+
+			if strings.HasPrefix(name, importMarker.Value) {
+				name = alias + name[len(importMarker.Value):]
+			}
+
+			visibleMarkers = append(visibleMarkers, visibleMarker{name: name, definition: definition})
+		}
+	}
+
+	return visibleMarkers
+}
+
+// importMarkers returns the import markers written in the given document.
+func (server *Server) importMarkers(doc *document) []markers.ImportMarker {
+	importMarkers := make([]markers.ImportMarker, 0)
+	importDefinition, exists := server.registry.Lookup(markers.ImportMarkerName, "", markers.PackageLevel)
+
+	for index := 0; exists && index < len(doc.lines); index++ {
+		line := doc.line(index)
+		start, isMarker := markerStart(line)
+
+		if !isMarker || findMarker([]visibleMarker{{name: markers.ImportMarkerName}}, line[start:]) == nil {
+			continue
+		}
+
+		value, err := importDefinition.Parse(strings.TrimSpace(line[start:]))
+
+		if importMarker, ok := value.(markers.ImportMarker); ok && err == nil {
+			importMarkers = append(importMarkers, importMarker)
+		}
+	}
+
+	return importMarkers
+}
+
+// findMarker returns the marker written in the given marker text. The longest
+// marker name is preferred since the marker names can contain colons.
+func findMarker(visibleMarkers []visibleMarker, text string) *visibleMarker {
+	var found *visibleMarker
+
+	for index, marker := range visibleMarkers {
+		prefix := "+" + marker.name
+
+		if !strings.HasPrefix(text, prefix) || (found != nil && len(found.name) >= len(marker.name)) {
+			continue
+		}
+
+		if len(text) == len(prefix) || strings.ContainsRune(":= \t", rune(text[len(prefix)])) {
+			found = &visibleMarkers[index]
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,229 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/procyon-projects/marker"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+const testDocumentURI = "file:///demo/demo.go"
+
+const testDocument = `// +import=cache, Pkg=github.com/example/cache
+package demo
+
+// +cache:entries:Order=asc, Size=1
+func FindUsers() {}
+
+// +cache:entries:Order=desc, Tags={users}
+// +cache:index=Name
+func FindOrders() {}
+`
+
+const testCompletionDocument = `// +import=cache, Pkg=github.com/example/cache
+package demo
+
+// +ca
+// +cache:entries:Order=asc,
+// +cache:entries:Order=d
+// +cache:entries:Tags={users, o
+func FindUsers() {}
+`
+
+type cacheMarker struct {
+	Order   string   `parameter:"Order" enum:"asc,desc" required:"true" description:"order of the entries"`
+	Tags    []string `parameter:"Tags" enum:"users,orders"`
+	Enabled bool     `parameter:"Enabled"`
+}
+
+type testClient struct {
+	t      *testing.T
+	conn   *Conn
+	nextID int
+	done   chan error
+}
+
+func newTestClient(t *testing.T) *testClient {
+	registry := markers.NewRegistry()
+	assert.NoError(t, registry.Register("cache:entries", "github.com/example/cache", markers.FunctionLevel, &cacheMarker{}))
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	client := &testClient{
+		t:    t,
+		conn: NewConn(clientReader, clientWriter),
+		done: make(chan error, 1),
+	}
+
+	go func() {
+		client.done <- NewServer(registry).Serve(serverReader, serverWriter)
+		serverWriter.Close()
+	}()
+
+	return client
+}
+
+// request sends a request and returns its response, the notifications received before are skipped.
+func (client *testClient) request(method string, params any, result any) *ResponseError {
+	client.nextID++
+	assert.NoError(client.t, client.conn.Request(client.nextID, method, params))
+
+	for {
+		message, err := client.conn.Read()
+
+		if !assert.NoError(client.t, err) {
+			return nil
+		}
+
+		if message.Method != "" {
+			continue
+		}
+
+		assert.Equal(client.t, json.RawMessage(fmt.Sprint(client.nextID)), *message.ID)
+
+		if message.Error == nil && result != nil {
+			assert.NoError(client.t, json.Unmarshal(message.Result, result))
+		}
+
+		return message.Error
+	}
+}
+
+// notification waits for the notification with the given method.
+func (client *testClient) notification(method string, params any) {
+	for {
+		message, err := client.conn.Read()
+
+		if !assert.NoError(client.t, err) {
+			return
+		}
+
+		if message.Method == method {
+			assert.NoError(client.t, json.Unmarshal(message.Params, params))
+			return
+		}
+	}
+}
+
+func (client *testClient) complete(line, character int) []string {
+	var list CompletionList
+	client.request("textDocument/completion", TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: testDocumentURI},
+		Position:     Position{Line: line, Character: character},
+	}, &list)
+
+	labels := make([]string, 0, len(list.Items))
+
+	for _, item := range list.Items {
+		labels = append(labels, item.Label)
+	}
+
+	return labels
+}
+
+func (client *testClient) hover(line, character int) *Hover {
+	var hover *Hover
+	client.request("textDocument/hover", TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: testDocumentURI},
+		Position:     Position{Line: line, Character: character},
+	}, &hover)
+	return hover
+}
+
+func TestServer(t *testing.T) {
+	client := newTestClient(t)
+
+	var initializeResult InitializeResult
+	assert.Nil(t, client.request("initialize", map[string]any{}, &initializeResult))
+	assert.Equal(t, TextDocumentSyncFull, initializeResult.Capabilities.TextDocumentSync)
+	assert.True(t, initializeResult.Capabilities.HoverProvider)
+	assert.NotNil(t, initializeResult.Capabilities.CompletionProvider)
+	assert.NoError(t, client.conn.Notify("initialized", map[string]any{}))
+
+	assert.NoError(t, client.conn.Notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: testDocumentURI, LanguageID: "go", Version: 1, Text: testDocument},
+	}))
+
+	var diagnostics PublishDiagnosticsParams
+	client.notification("textDocument/publishDiagnostics", &diagnostics)
+	assert.Equal(t, testDocumentURI, diagnostics.URI)
+	assert.Equal(t, []Diagnostic{
+		{
+			Range: Range{
+				Start: Position{Line: 3, Character: 29},
+				End:   Position{Line: 3, Character: 35},
+			},
+			Severity: SeverityError,
+			Source:   DiagnosticSource,
+			Message:  "the marker 'cache:entries' does not have an argument named 'Size'",
+		},
+		{
+			Range: Range{
+				Start: Position{Line: 7, Character: 3},
+				End:   Position{Line: 7, Character: 20},
+			},
+			Severity: SeverityWarning,
+			Source:   DiagnosticSource,
+			Message:  "the marker 'cache:index' cannot be resolved",
+		},
+	}, diagnostics.Diagnostics)
+
+	hover := client.hover(3, 6)
+	if assert.NotNil(t, hover) {
+		assert.Contains(t, hover.Contents.Value, "# cache:entries")
+		assert.Equal(t, &Range{Start: Position{Line: 3, Character: 3}, End: Position{Line: 3, Character: 17}}, hover.Range)
+	}
+
+	hover = client.hover(3, 19)
+	if assert.NotNil(t, hover) {
+		assert.Equal(t, "**Order** `String (required)`\n\norder of the entries", hover.Contents.Value)
+	}
+
+	assert.Nil(t, client.hover(4, 3))
+
+	assert.NoError(t, client.conn.Notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: testDocumentURI, Version: 2},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: testCompletionDocument}},
+	}))
+	client.notification("textDocument/publishDiagnostics", &diagnostics)
+
+	assert.Equal(t, []string{"cache:entries"}, client.complete(3, 6))
+	assert.Equal(t, []string{"Enabled", "Tags"}, client.complete(4, 29))
+	assert.Equal(t, []string{"desc"}, client.complete(5, 26))
+	assert.Equal(t, []string{"orders"}, client.complete(6, 32))
+
+	responseErr := client.request("textDocument/definition", map[string]any{}, nil)
+	if assert.NotNil(t, responseErr) {
+		assert.Equal(t, MethodNotFound, responseErr.Code)
+	}
+
+	assert.Nil(t, client.request("shutdown", nil, nil))
+	assert.NoError(t, client.conn.Notify("exit", nil))
+	assert.NoError(t, <-client.done)
+}
+
+func TestServer_DidClose(t *testing.T) {
+	client := newTestClient(t)
+
+	assert.NoError(t, client.conn.Notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: testDocumentURI, LanguageID: "go", Version: 1, Text: "package demo\n\n// +cache:entries:Order=asc\nfunc FindUsers() {}\n"},
+	}))
+
+	var diagnostics PublishDiagnosticsParams
+	client.notification("textDocument/publishDiagnostics", &diagnostics)
+	assert.Len(t, diagnostics.Diagnostics, 0)
+	assert.Empty(t, client.complete(2, 6))
+
+	assert.NoError(t, client.conn.Notify("textDocument/didClose", DidCloseTextDocumentParams{
+		TextDocument: TextDocumentIdentifier{URI: testDocumentURI},
+	}))
+	client.notification("textDocument/publishDiagnostics", &diagnostics)
+	assert.Len(t, diagnostics.Diagnostics, 0)
+	assert.Nil(t, client.hover(2, 6))
+
+	assert.NoError(t, client.conn.Notify("exit", nil))
+	assert.NoError(t, <-client.done)
+}
@@ -1,15 +1,97 @@
 package markers
 
 import (
+	"github.com/procyon-projects/marker/packages"
 	"go/ast"
 	"go/token"
+	"reflect"
+	"sort"
 	"strings"
+	"unicode"
 )
 
+// Validate is implemented by the marker outputs which validate their own values.
 type Validate interface {
 	Validate() error
 }
 
+// ValidateWithContext is implemented by the marker outputs which need to know
+// the node they are associated with while validating their values.
+type ValidateWithContext interface {
+	ValidateWithContext(ctx ValidationContext) error
+}
+
+// ValidationContext describes the node a marker is associated with.
+type ValidationContext struct {
+	Node        ast.Node
+	TargetLevel TargetLevel
+	Package     *packages.Package
+}
+
+// Name returns the name of the node. It returns an empty string
+// if the node does not have any name such as an embedded field or a file.
+func (ctx ValidationContext) Name() string {
+	switch typedNode := ctx.Node.(type) {
+	case *ast.TypeSpec:
+		return typedNode.Name.Name
+	case *ast.FuncDecl:
+		return typedNode.Name.Name
+	case *ast.Field:
+		if len(typedNode.Names) != 0 {
+			return typedNode.Names[0].Name
+		}
+	case *ast.File:
+		return typedNode.Name.Name
+	}
+
+	return ""
+}
+
+// IsExported reports whether the node is exported.
+func (ctx ValidationContext) IsExported() bool {
+	return ast.IsExported(ctx.Name())
+}
+
+// validateMarker invokes the validation methods of the given marker value.
+// The methods with pointer receivers are also taken into account.
+func validateMarker(value any, ctx ValidationContext) error {
+	if value == nil {
+		return nil
+	}
+
+	pointerValue := reflect.New(reflect.TypeOf(value))
+	pointerValue.Elem().Set(reflect.ValueOf(value))
+	pointer := pointerValue.Interface()
+
+	var errs []error
+
+	if marker, ok := value.(Validate); ok {
+		errs = appendError(errs, marker.Validate())
+	} else if marker, ok = pointer.(Validate); ok {
+		errs = appendError(errs, marker.Validate())
+	}
+
+	if marker, ok := value.(ValidateWithContext); ok {
+		errs = appendError(errs, marker.ValidateWithContext(ctx))
+	} else if marker, ok = pointer.(ValidateWithContext); ok {
+		errs = appendError(errs, marker.ValidateWithContext(ctx))
+	}
+
+	return NewErrorList(errs)
+}
+
+func appendError(errs []error, err error) []error {
+	if err == nil {
+		return errs
+	}
+
+	if errorList, ok := err.(ErrorList); ok {
+		return append(errs, errorList...)
+	}
+
+	return append(errs, err)
+}
+
 type MarkerValues map[string][]any
 
 func (markerValues MarkerValues) Count() int {
@@ -47,6 +129,42 @@ func (markerValues MarkerValues) CountByName(name string) int {
 	return len(result)
 }
 
+// Names returns the names of the markers in sorted order.
+func (markerValues MarkerValues) Names() []string {
+	names := make([]string, 0, len(markerValues))
+
+	for name := range markerValues {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Marker is a marker value with the position of its comment.
+type Marker struct {
+	Name     string
+	Value    any
+	Position token.Position
+}
+
+// NodeMarkers contains the markers of a node in the order they are declared.
+type NodeMarkers struct {
+	Node    ast.Node
+	Markers []Marker
+}
+
+// Values returns the marker values of the node keyed by the marker names.
+func (nodeMarkers NodeMarkers) Values() MarkerValues {
+	markerValues := make(MarkerValues)
+
+	for _, marker := range nodeMarkers.Markers {
+		markerValues[marker.Name] = append(markerValues[marker.Name], marker.Value)
+	}
+
+	return markerValues
+}
+
 type markerComment struct {
 	commentLines []*ast.Comment
 }
@@ -66,7 +184,7 @@ func (c markerComment) Pos() token.Pos {
 }
 
 func (c markerComment) End() token.Pos {
-	return c.commentLines[len(c.commentLines)].End()
+	return c.commentLines[len(c.commentLines)-1].End()
 }
 
 func (c *markerComment) append(comment *ast.Comment) {
@@ -76,11 +194,7 @@ func (c *markerComment) append(comment *ast.Comment) {
 func (c *markerComment) Text() string {
 	var text string
 	for _, line := range c.commentLines {
-		comment := strings.TrimSpace(line.Text[2:])
-
-		if strings.HasSuffix(comment, "\\") {
-			comment = strings.TrimSpace(comment[:len(comment)-1])
-		}
+		comment, _ := markerCommentLine(line)
 
 		if text == "" {
 			text = comment
@@ -91,6 +205,38 @@ func (c *markerComment) Text() string {
 	return text
 }
 
+// offsetPos returns the position of the character at the given offset of the marker text.
+// The offsets are translated across the lines of the multi-line marker comments.
+func (c markerComment) offsetPos(offset int) token.Pos {
+	lineOffset := 0
+
+	for index, line := range c.commentLines {
+		comment, start := markerCommentLine(line)
+
+		if offset <= lineOffset+len(comment) || index == len(c.commentLines)-1 {
+			return line.Slash + token.Pos(start+offset-lineOffset)
+		}
+
+		lineOffset += len(comment) + 1
+	}
+
+	return c.Pos()
+}
+
+// markerCommentLine returns the marker text in the given comment line
+// with its offset within the line.
+func markerCommentLine(line *ast.Comment) (string, int) {
+	comment := strings.TrimLeftFunc(line.Text[2:], unicode.IsSpace)
+	start := len(line.Text) - len(comment)
+	comment = strings.TrimRightFunc(comment, unicode.IsSpace)
+
+	if strings.HasSuffix(comment, "\\") {
+		comment = strings.TrimSpace(comment[:len(comment)-1])
+	}
+
+	return comment, start
+}
+
 func splitMarker(marker string) (name string, anonymousName string, options string) {
 	marker = marker[1:]
 
@@ -43,3 +43,27 @@ func TestMarkerValues_First(t *testing.T) {
 	assert.Equal(t, "anyTest1", markerValues.First("anyMarker1"))
 	assert.Equal(t, "anyTest3", markerValues.First("anyMarker2"))
 }
+
+func TestMarkerValues_Names(t *testing.T) {
+	markerValues := make(MarkerValues)
+	markerValues["anyMarker2"] = append(markerValues["anyMarker2"], "anyTest1")
+	markerValues["anyMarker1"] = append(markerValues["anyMarker1"], "anyTest2")
+	markerValues["anyMarker3"] = append(markerValues["anyMarker3"], "anyTest3")
+
+	assert.Equal(t, []string{"anyMarker1", "anyMarker2", "anyMarker3"}, markerValues.Names())
+}
+
+func TestNodeMarkers_Values(t *testing.T) {
+	nodeMarkers := NodeMarkers{
+		Markers: []Marker{
+			{Name: "anyMarker1", Value: "anyTest1"},
+			{Name: "anyMarker2", Value: "anyTest2"},
+			{Name: "anyMarker1", Value: "anyTest3"},
+		},
+	}
+
+	assert.Equal(t, MarkerValues{
+		"anyMarker1": {"anyTest1", "anyTest3"},
+		"anyMarker2": {"anyTest2"},
+	}, nodeMarkers.Values())
+}
@@ -0,0 +1,630 @@
+package markers
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MigrationKind is the kind of a migration rule.
+type MigrationKind string
+
+const (
+	// RenameMarker renames the marker to To.
+	RenameMarker MigrationKind = "rename-marker"
+	// RenameArgument renames the argument of the marker to To.
+	RenameArgument MigrationKind = "rename-argument"
+	// MapEnumValue replaces the From value of the argument of the marker with To.
+	MapEnumValue MigrationKind = "map-enum-value"
+	// DropArgument removes the argument of the marker.
+	DropArgument MigrationKind = "drop-argument"
+	// ChangeAlias changes the alias which the markers of the package are imported with to To.
+	// If From is specified, only the imports having the alias From are changed.
+	ChangeAlias MigrationKind = "change-alias"
+)
+
+// MigrationRule describes a change in the markers of a processor package. The markers are
+// specified with their names in the package, the rules are applied in order.
+type MigrationRule struct {
+	Kind     MigrationKind `json:"kind" yaml:"kind"`
+	Pkg      string        `json:"pkg" yaml:"pkg"`
+	Marker   string        `json:"marker,omitempty" yaml:"marker,omitempty"`
+	Argument string        `json:"argument,omitempty" yaml:"argument,omitempty"`
+	From     string        `json:"from,omitempty" yaml:"from,omitempty"`
+	To       string        `json:"to,omitempty" yaml:"to,omitempty"`
+}
+
+func (rule MigrationRule) validate() error {
+	if rule.Pkg == "" {
+		return fmt.Errorf("pkg of '%s' rule cannot be empty", rule.Kind)
+	}
+
+	switch rule.Kind {
+	case RenameMarker:
+		return rule.require(rule.Marker, "marker", rule.To, "to")
+	case RenameArgument:
+		return rule.require(rule.Marker, "marker", rule.Argument, "argument", rule.To, "to")
+	case MapEnumValue:
+		return rule.require(rule.Marker, "marker", rule.Argument, "argument", rule.From, "from", rule.To, "to")
+	case DropArgument:
+		return rule.require(rule.Marker, "marker", rule.Argument, "argument")
+	case ChangeAlias:
+		return rule.require(rule.To, "to")
+	}
+
+	return fmt.Errorf("rule kind '%s' is not supported", rule.Kind)
+}
+
+// require returns an error if any of the given values is empty. The values are followed by their names.
+func (rule MigrationRule) require(valuesAndNames ...string) error {
+	for index := 0; index < len(valuesAndNames); index += 2 {
+		if valuesAndNames[index] == "" {
+			return fmt.Errorf("%s of '%s' rule cannot be empty", valuesAndNames[index+1], rule.Kind)
+		}
+	}
+
+	return nil
+}
+
+// ValidateMigrationRules returns an error if any of the rules is not valid.
+func ValidateMigrationRules(rules []MigrationRule) error {
+	var errs []error
+
+	for index, rule := range rules {
+		if err := rule.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("rule %d: %w", index+1, err))
+		}
+	}
+
+	return NewErrorList(errs)
+}
+
+// MigrationProblem describes a marker occurrence which cannot be migrated.
+type MigrationProblem struct {
+	FileName string
+	Position Position
+	Marker   string
+	Message  string
+}
+
+func (problem MigrationProblem) String() string {
+	return fmt.Sprintf("%s (%d:%d) : %s: %s", problem.FileName, problem.Position.Line, problem.Position.Column, problem.Marker, problem.Message)
+}
+
+// MigrateSource applies the migration rules to the marker comments of the declarations in the given
+// Go source, and returns the migrated source with the occurrences which cannot be migrated. The markers
+// are matched by their names in the processor packages which they are imported from, so the rules do
+// not depend on the definitions of the markers. The migrated markers are rewritten in a single line
+// unless they exceed the default format width.
+func MigrateSource(fileName string, src []byte, rules []MigrationRule) ([]byte, []MigrationProblem, error) {
+	err := ValidateMigrationRules(rules)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, fileName, src, parser.ParseComments)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	registry := NewRegistry()
+	importDefinition, _ := registry.Lookup(ImportMarkerName, "", PackageLevel)
+	markerComments := declarationMarkerComments(file)
+	migration := &sourceMigration{
+		fileSet:          fileSet,
+		rules:            rules,
+		importDefinition: importDefinition,
+		importAliases:    fileImportAliases(registry, markerComments),
+	}
+	migration.migrateAliases()
+
+	migrated := rewriteMarkerComments(fileSet, src, markerComments, DefaultFormatWidth, func(comment markerComment, width int) []string {
+		return migration.migrateMarkerComment(comment, width)
+	})
+
+	return migrated, migration.problems, nil
+}
+
+// declarationMarkerComments returns the marker comments which are associated with the declarations
+// in the file in the order they appear.
+func declarationMarkerComments(file *ast.File) []markerComment {
+	markerComments := make([]markerComment, 0)
+
+	for _, nodeMarkerComments := range NewCollector(NewRegistry()).collectFileMarkerComments(file) {
+		markerComments = append(markerComments, nodeMarkerComments...)
+	}
+
+	sort.Slice(markerComments, func(i, j int) bool {
+		return markerComments[i].Pos() < markerComments[j].Pos()
+	})
+
+	return markerComments
+}
+
+type sourceMigration struct {
+	fileSet          *token.FileSet
+	rules            []MigrationRule
+	importDefinition *Definition
+	importAliases    AliasMap
+	// aliases maps the aliases in the source to the aliases which they are changed into.
+	aliases map[string]string
+	// aliasConflicts contains the problems of the aliases which cannot be changed.
+	aliasConflicts map[string]string
+	problems       []MigrationProblem
+}
+
+// migrateAliases finds the aliases which are changed by the rules. The aliases conflicting
+// with another import are not changed.
+func (migration *sourceMigration) migrateAliases() {
+	aliases := make([]string, 0, len(migration.importAliases))
+
+	for alias := range migration.importAliases {
+		aliases = append(aliases, alias)
+	}
+
+	sort.Strings(aliases)
+	migration.aliases = make(map[string]string, len(aliases))
+	migration.aliasConflicts = make(map[string]string)
+
+	for _, alias := range aliases {
+		newAlias := alias
+
+		for _, rule := range migration.rules {
+			if rule.Kind == ChangeAlias && rule.Pkg == migration.importAliases[alias].Pkg && (rule.From == "" || rule.From == newAlias) {
+				newAlias = rule.To
+			}
+		}
+
+		migration.aliases[alias] = newAlias
+	}
+
+	for _, alias := range aliases {
+		newAlias := migration.aliases[alias]
+
+		if newAlias == alias {
+			continue
+		}
+
+		for _, otherAlias := range aliases {
+			if otherAlias != alias && migration.aliases[otherAlias] == newAlias {
+				migration.aliasConflicts[alias] = fmt.Sprintf("alias '%s' cannot be changed into '%s' since it is used by the import of '%s'",
+					alias, newAlias, migration.importAliases[otherAlias].Pkg)
+				break
+			}
+		}
+	}
+
+	for alias := range migration.aliasConflicts {
+		migration.aliases[alias] = alias
+	}
+}
+
+// importAlias returns the alias of the given import marker.
+func (migration *sourceMigration) importAlias(text string) string {
+	value, err := migration.importDefinition.Parse(text)
+	importMarker, isImportMarker := value.(ImportMarker)
+
+	if err != nil || !isImportMarker {
+		return ""
+	}
+
+	if importMarker.Alias == "" {
+		return importMarker.Value
+	}
+
+	return importMarker.Alias
+}
+
+// migrateMarkerComment returns the lines of the migrated marker comment. It returns nil
+// if the marker is not changed.
+func (migration *sourceMigration) migrateMarkerComment(comment markerComment, width int) []string {
+	text := comment.Text()
+	name, anonymousName, _ := splitMarker(text)
+
+	if name == ImportMarkerName && anonymousName == ImportMarkerName {
+		return migration.migrateImportMarker(comment, width)
+	}
+
+	alias := strings.SplitN(name, ":", 2)[0]
+	importMarker, imported := migration.importAliases[alias]
+
+	if !imported {
+		return nil
+	}
+
+	// the markers are matched by their names in the processor package
+	markerText := importMarker.Value + text[len(alias)+1:]
+	markerName := migration.findMarker(importMarker.Pkg, markerText)
+	newAlias := migration.aliases[alias]
+
+	if markerName == "" {
+		return migration.changeAlias(comment, alias, newAlias)
+	}
+
+	marker := &markerMigration{
+		name:      markerName,
+		isChanged: newAlias != alias,
+	}
+
+	err := marker.split(markerText[len(markerName):])
+
+	if err != nil {
+		migration.addProblem(comment, markerName, fmt.Sprintf("arguments cannot be parsed: %v", err))
+		return migration.changeAlias(comment, alias, newAlias)
+	}
+
+	for _, rule := range migration.rules {
+		if rule.Pkg != importMarker.Pkg || rule.Marker != marker.name {
+			continue
+		}
+
+		err = marker.apply(rule, importMarker)
+
+		if err != nil {
+			migration.addProblem(comment, markerName, err.Error())
+			return migration.changeAlias(comment, alias, newAlias)
+		}
+	}
+
+	if !marker.isChanged {
+		return nil
+	}
+
+	return marker.lines(newAlias+strings.TrimPrefix(marker.name, importMarker.Value), width)
+}
+
+// changeAlias returns the lines of the marker comment in which only the alias is changed.
+// It returns nil if the alias is not changed.
+func (migration *sourceMigration) changeAlias(comment markerComment, alias, newAlias string) []string {
+	if newAlias == alias {
+		return nil
+	}
+
+	lines := make([]string, 0, len(comment.commentLines))
+
+	for _, line := range comment.commentLines {
+		lines = append(lines, strings.TrimSpace(strings.TrimPrefix(line.Text, "//")))
+	}
+
+	lines[0] = "+" + newAlias + strings.TrimPrefix(lines[0], "+"+alias)
+	return lines
+}
+
+// migrateImportMarker returns the lines of the import marker whose alias is changed.
+func (migration *sourceMigration) migrateImportMarker(comment markerComment, width int) []string {
+	alias := migration.importAlias(comment.Text())
+
+	if message, conflicts := migration.aliasConflicts[alias]; conflicts {
+		migration.addProblem(comment, ImportMarkerName, message)
+		return nil
+	}
+
+	newAlias, exists := migration.aliases[alias]
+
+	if !exists || newAlias == alias {
+		return nil
+	}
+
+	importMarker := migration.importAliases[alias]
+	marker := &markerMigration{
+		name: ImportMarkerName,
+	}
+
+	err := marker.split(comment.Text()[len(ImportMarkerName)+1:])
+
+	if err != nil {
+		migration.addProblem(comment, ImportMarkerName, fmt.Sprintf("arguments cannot be parsed: %v", err))
+		return nil
+	}
+
+	marker.remove("Alias")
+
+	if newAlias != importMarker.Value {
+		marker.arguments = append(marker.arguments, markerArgument{Name: "Alias", Value: formatString(newAlias, false)})
+	}
+
+	return marker.lines(ImportMarkerName, width)
+}
+
+// findMarker returns the longest marker name in the rules of the package which the given marker text starts with.
+// It returns an empty string if the name is shorter than the marker name written, the rules of a marker are not
+// applied to the markers whose names start with it such as 'cache' and 'cache:memo:Order=asc'.
+func (migration *sourceMigration) findMarker(pkg, markerText string) string {
+	markerName := ""
+	writtenName, _, _ := splitMarker("+" + markerText)
+
+	for _, rule := range migration.rules {
+		if rule.Pkg != pkg || rule.Marker == "" || len(rule.Marker) <= len(markerName) || !strings.HasPrefix(markerText, rule.Marker) {
+			continue
+		}
+
+		if len(markerText) == len(rule.Marker) || strings.ContainsRune(":= \t", rune(markerText[len(rule.Marker)])) {
+			markerName = rule.Marker
+		}
+	}
+
+	if len(markerName) < len(writtenName) {
+		return ""
+	}
+
+	return markerName
+}
+
+func (migration *sourceMigration) addProblem(comment markerComment, markerName, message string) {
+	position := migration.fileSet.Position(comment.Pos())
+	migration.problems = append(migration.problems, MigrationProblem{
+		FileName: position.Filename,
+		Position: Position{
+			Line:   position.Line,
+			Column: position.Column,
+		},
+		Marker:  markerName,
+		Message: message,
+	})
+}
+
+// markerArgument is an argument written in a marker comment.
+type markerArgument struct {
+	Name string
+	// Value is the text of the value as it is written.
+	Value string
+}
+
+type markerMigration struct {
+	name      string
+	arguments []markerArgument
+	// syntaxFreeText is the text following the name of the syntax-free markers.
+	syntaxFreeText string
+	isSyntaxFree   bool
+	isChanged      bool
+}
+
+// split splits the given text following the marker name into the arguments.
+func (marker *markerMigration) split(text string) error {
+	switch {
+	case text == "":
+		return nil
+	case text[0] == ' ' || text[0] == '\t':
+		marker.isSyntaxFree = true
+		marker.syntaxFreeText = strings.TrimSpace(text)
+		return nil
+	case text[0] == ':':
+		text = text[1:]
+	}
+
+	parts, err := splitTopLevel(text)
+
+	if err != nil {
+		return err
+	}
+
+	for index, part := range parts {
+		if part == "" {
+			continue
+		}
+
+		if index == 0 && part[0] == '=' {
+			marker.arguments = append(marker.arguments, markerArgument{Name: ValueArgument, Value: strings.TrimSpace(part[1:])})
+			continue
+		}
+
+		name, value, found := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+
+		if !found || !token.IsIdentifier(name) {
+			return fmt.Errorf("got %q; want Argument name", part)
+		}
+
+		marker.arguments = append(marker.arguments, markerArgument{Name: name, Value: strings.TrimSpace(value)})
+	}
+
+	return nil
+}
+
+// apply applies the given rule to the marker.
+func (marker *markerMigration) apply(rule MigrationRule, importMarker ImportMarker) error {
+	if marker.isSyntaxFree && rule.Kind != RenameMarker {
+		return fmt.Errorf("arguments of the syntax-free marker cannot be migrated")
+	}
+
+	switch rule.Kind {
+	case RenameMarker:
+		if rule.To != importMarker.Value && !strings.HasPrefix(rule.To, importMarker.Value+":") {
+			return fmt.Errorf("marker cannot be renamed into '%s' since the markers of '%s' are imported as '%s'", rule.To, importMarker.Pkg, importMarker.Value)
+		}
+
+		marker.name = rule.To
+		marker.isChanged = true
+	case RenameArgument:
+		index := marker.find(rule.Argument)
+
+		if index == -1 {
+			return nil
+		}
+
+		if marker.find(rule.To) != -1 {
+			return fmt.Errorf("argument '%s' cannot be renamed into '%s' since it already exists", rule.Argument, rule.To)
+		}
+
+		marker.arguments[index].Name = rule.To
+		marker.isChanged = true
+	case MapEnumValue:
+		index := marker.find(rule.Argument)
+
+		if index == -1 {
+			return nil
+		}
+
+		value, mapped, err := mapEnumValue(marker.arguments[index].Value, rule.From, rule.To)
+
+		if err != nil {
+			return fmt.Errorf("value of argument '%s' cannot be mapped: %w", rule.Argument, err)
+		}
+
+		if mapped {
+			marker.arguments[index].Value = value
+			marker.isChanged = true
+		}
+	case DropArgument:
+		if marker.remove(rule.Argument) {
+			marker.isChanged = true
+		}
+	}
+
+	return nil
+}
+
+// find returns the index of the argument with the given name, or -1 if it does not exist.
+func (marker *markerMigration) find(name string) int {
+	for index, argument := range marker.arguments {
+		if argument.Name == name {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// remove removes the argument with the given name and reports whether it exists.
+func (marker *markerMigration) remove(name string) bool {
+	index := marker.find(name)
+
+	if index == -1 {
+		return false
+	}
+
+	marker.arguments = append(marker.arguments[:index], marker.arguments[index+1:]...)
+	return true
+}
+
+// lines returns the marker lines written with the given name. The value argument is written first.
+func (marker *markerMigration) lines(name string, width int) []string {
+	if marker.isSyntaxFree {
+		return []string{"+" + name + " " + marker.syntaxFreeText}
+	}
+
+	arguments := make([]string, 0, len(marker.arguments))
+
+	if index := marker.find(ValueArgument); index != -1 {
+		arguments = append(arguments, "="+marker.arguments[index].Value)
+	}
+
+	for _, argument := range marker.arguments {
+		if argument.Name != ValueArgument {
+			arguments = append(arguments, argument.Name+"="+argument.Value)
+		}
+	}
+
+	return wrapMarker(name, arguments, width)
+}
+
+// mapEnumValue replaces the given value or the items of the given list with the value to
+// if they are equal to the value from. It reports whether any value is replaced.
+func mapEnumValue(value, from, to string) (string, bool, error) {
+	if !strings.HasPrefix(value, "{") {
+		return mapEnumItem(value, from, to)
+	}
+
+	if !strings.HasSuffix(value, "}") {
+		return "", false, fmt.Errorf("'}' is missing")
+	}
+
+	items, err := splitTopLevel(value[1 : len(value)-1])
+
+	if err != nil {
+		return "", false, err
+	}
+
+	isMapped := false
+	mappedItems := make([]string, 0, len(items))
+
+	for _, item := range items {
+		if item == "" {
+			continue
+		}
+
+		mappedItem, mapped, err := mapEnumItem(item, from, to)
+
+		if err != nil {
+			return "", false, err
+		}
+
+		isMapped = isMapped || mapped
+		mappedItems = append(mappedItems, mappedItem)
+	}
+
+	if !isMapped {
+		return value, false, nil
+	}
+
+	return "{" + strings.Join(mappedItems, ", ") + "}", true, nil
+}
+
+func mapEnumItem(item, from, to string) (string, bool, error) {
+	text := item
+
+	if strings.HasPrefix(item, "\"") || strings.HasPrefix(item, "`") {
+		unquoted, err := strconv.Unquote(item)
+
+		if err != nil {
+			return "", false, err
+		}
+
+		text = unquoted
+	} else if strings.ContainsAny(item, "{}:") {
+		return "", false, fmt.Errorf("%s is not a string", item)
+	}
+
+	if text != from {
+		return item, false, nil
+	}
+
+	return formatString(to, false), true, nil
+}
+
+// splitTopLevel splits the given text at the commas which are not in braces or strings.
+func splitTopLevel(text string) ([]string, error) {
+	var errs []error
+	scanner := NewScanner(text)
+	scanner.ErrorCallback = func(scanner *Scanner, message string) {
+		errs = append(errs, scanner.newError(message))
+	}
+
+	parts := make([]string, 0)
+	start, depth := 0, 0
+
+	for token := scanner.Scan(); token != EOF && len(errs) == 0; token = scanner.Scan() {
+		switch token {
+		case '{':
+			depth++
+		case '}':
+			depth--
+
+			if depth < 0 {
+				return nil, fmt.Errorf("unexpected '}'")
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(text[start:scanner.TokenPosition()]))
+				start = scanner.TokenPosition() + 1
+			}
+		}
+	}
+
+	if len(errs) != 0 {
+		return nil, NewErrorList(errs)
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("'}' is missing")
+	}
+
+	return append(parts, strings.TrimSpace(text[start:])), nil
+}
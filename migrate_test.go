@@ -0,0 +1,145 @@
+package markers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const migrationSource = `// +import=cache, Pkg=github.com/example/cache
+// +import=other, Pkg=github.com/example/other, Alias=store
+package demo
+
+// Users returns the users.
+// +cache:memo:Ordr=asc, Tags={users, "orders"}, Legacy=true
+// +store:memo:Ordr=asc
+func Users() {}
+
+// +cache:memo:Ordr=desc, \
+// Order=asc
+// +cache:entries=users, Order="asc"
+// +cache:memo:Tags={users: true}
+func Orders() {
+	// +cache:memo:Ordr=asc
+}
+
+// +cache:notes keep the text
+// +cache:memo:Ordr=asc, Tags={users
+type Notes struct{}
+`
+
+var migrationRules = []MigrationRule{
+	{Kind: RenameMarker, Pkg: "github.com/example/cache", Marker: "cache:memo", To: "cache:entries"},
+	{Kind: RenameArgument, Pkg: "github.com/example/cache", Marker: "cache:entries", Argument: "Ordr", To: "Order"},
+	{Kind: MapEnumValue, Pkg: "github.com/example/cache", Marker: "cache:entries", Argument: "Order", From: "asc", To: "ascending"},
+	{Kind: MapEnumValue, Pkg: "github.com/example/cache", Marker: "cache:entries", Argument: "Tags", From: "users", To: "people"},
+	{Kind: DropArgument, Pkg: "github.com/example/cache", Marker: "cache:entries", Argument: "Legacy"},
+	{Kind: MapEnumValue, Pkg: "github.com/example/cache", Marker: "cache:notes", Argument: "Value", From: "a", To: "b"},
+	{Kind: ChangeAlias, Pkg: "github.com/example/cache", To: "ch"},
+}
+
+func TestMigrateSource(t *testing.T) {
+	migrated, problems, err := MigrateSource("demo.go", []byte(migrationSource), migrationRules)
+	assert.NoError(t, err)
+	assert.Equal(t, `// +import=cache, Pkg=github.com/example/cache, Alias=ch
+// +import=other, Pkg=github.com/example/other, Alias=store
+package demo
+
+// Users returns the users.
+// +ch:entries:Order=ascending, Tags={people, "orders"}
+// +store:memo:Ordr=asc
+func Users() {}
+
+// +ch:memo:Ordr=desc, \
+// Order=asc
+// +ch:entries=users, Order=ascending
+// +ch:memo:Tags={users: true}
+func Orders() {
+	// +ch:entries:Order=ascending
+}
+
+// +ch:notes keep the text
+// +ch:memo:Ordr=asc, Tags={users
+type Notes struct{}
+`, string(migrated))
+
+	assert.Equal(t, []MigrationProblem{
+		{FileName: "demo.go", Position: Position{Line: 10, Column: 1}, Marker: "cache:memo", Message: "argument 'Ordr' cannot be renamed into 'Order' since it already exists"},
+		{FileName: "demo.go", Position: Position{Line: 13, Column: 1}, Marker: "cache:memo", Message: "value of argument 'Tags' cannot be mapped: users: true is not a string"},
+		{FileName: "demo.go", Position: Position{Line: 18, Column: 1}, Marker: "cache:notes", Message: "arguments of the syntax-free marker cannot be migrated"},
+		{FileName: "demo.go", Position: Position{Line: 19, Column: 1}, Marker: "cache:memo", Message: "arguments cannot be parsed: '}' is missing"},
+	}, problems)
+	assert.Equal(t, "demo.go (10:1) : cache:memo: argument 'Ordr' cannot be renamed into 'Order' since it already exists", problems[0].String())
+}
+
+func TestMigrateSource_ChangeAlias(t *testing.T) {
+	source := `// +import=cache, Pkg=github.com/example/cache, Alias=c
+// +import=other, Pkg=github.com/example/other
+package demo
+
+// +c:entries:Order=asc
+// +other:memo
+func Users() {}
+`
+
+	migrated, problems, err := MigrateSource("demo.go", []byte(source), []MigrationRule{
+		{Kind: ChangeAlias, Pkg: "github.com/example/cache", From: "c", To: "cache"},
+		{Kind: ChangeAlias, Pkg: "github.com/example/other", To: "cache"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, source, string(migrated))
+	assert.Len(t, problems, 2)
+	assert.Equal(t, "alias 'c' cannot be changed into 'cache' since it is used by the import of 'github.com/example/other'", problems[0].Message)
+
+	migrated, problems, err = MigrateSource("demo.go", []byte(source), []MigrationRule{
+		{Kind: ChangeAlias, Pkg: "github.com/example/cache", From: "c", To: "cache"},
+		{Kind: RenameMarker, Pkg: "github.com/example/other", Marker: "other:memo", To: "cache:memo"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `// +import=cache, Pkg=github.com/example/cache
+// +import=other, Pkg=github.com/example/other
+package demo
+
+// +cache:entries:Order=asc
+// +other:memo
+func Users() {}
+`, string(migrated))
+	assert.Equal(t, []MigrationProblem{
+		{FileName: "demo.go", Position: Position{Line: 6, Column: 1}, Marker: "other:memo", Message: "marker cannot be renamed into 'cache:memo' since the markers of 'github.com/example/other' are imported as 'other'"},
+	}, problems)
+}
+
+func TestMigrateSource_ShouldSkipMarkersWithLongerNames(t *testing.T) {
+	source := `// +import=cache, Pkg=github.com/example/cache
+package demo
+
+// +cache:memo:Name=x, Ordr=asc
+// +cache:Ordr=desc
+func Users() {}
+`
+
+	migrated, problems, err := MigrateSource("demo.go", []byte(source), []MigrationRule{
+		{Kind: RenameArgument, Pkg: "github.com/example/cache", Marker: "cache", Argument: "Ordr", To: "Order"},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, problems)
+	assert.Equal(t, `// +import=cache, Pkg=github.com/example/cache
+package demo
+
+// +cache:memo:Name=x, Ordr=asc
+// +cache:Order=desc
+func Users() {}
+`, string(migrated))
+}
+
+func TestValidateMigrationRules(t *testing.T) {
+	err := ValidateMigrationRules([]MigrationRule{
+		{Kind: RenameMarker, Pkg: "anyPkg", Marker: "cache:memo"},
+		{Kind: "rename", Pkg: "anyPkg"},
+		{Kind: DropArgument, Marker: "cache:memo", Argument: "Legacy"},
+		{Kind: ChangeAlias, Pkg: "anyPkg", To: "c"},
+	})
+	assert.EqualError(t, err, "[rule 1: to of 'rename-marker' rule cannot be empty rule 2: rule kind 'rename' is not supported rule 3: pkg of 'drop-argument' rule cannot be empty]")
+
+	_, _, err = MigrateSource("demo.go", []byte("package demo"), []MigrationRule{{Kind: DropArgument}})
+	assert.Error(t, err)
+}
@@ -7,6 +7,7 @@ import (
 	"go/token"
 	"golang.org/x/tools/go/packages"
 	"os/exec"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -79,17 +80,23 @@ type LoadResult struct {
 	standardPackages map[string]*Package
 }
 
+// Packages returns the loaded packages sorted by their ids, followed by the standard packages.
 func (result *LoadResult) Packages() []*Package {
-	pkgs := make([]*Package, 0)
+	pkgs := sortedPackages(result.packages)
+	return append(pkgs, sortedPackages(result.standardPackages)...)
+}
 
-	for _, pkg := range result.packages {
-		pkgs = append(pkgs, pkg)
-	}
+func sortedPackages(packageMap map[string]*Package) []*Package {
+	pkgs := make([]*Package, 0, len(packageMap))
 
-	for _, pkg := range result.standardPackages {
+	for _, pkg := range packageMap {
 		pkgs = append(pkgs, pkg)
 	}
 
+	sort.Slice(pkgs, func(i, j int) bool {
+		return pkgs[i].ID < pkgs[j].ID
+	})
+
 	return pkgs
 }
 
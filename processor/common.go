@@ -2,7 +2,10 @@ package processor
 
 import (
 	"encoding/json"
+	"errors"
+	"github.com/procyon-projects/marker"
 	"github.com/procyon-projects/marker/packages"
+	"go/ast"
 	"os"
 	"path"
 	"path/filepath"
@@ -103,3 +106,90 @@ func findDirectoriesWithGoFiles(root string) ([]string, error) {
 
 	return dirs, nil
 }
+
+// loadModuleDefinitions loads the packages of the go module and returns the definitions of the markers
+// which can be used in the packages. These are the reserved markers, the markers declared in the packages,
+// and the markers of the processors imported by the packages.
+func loadModuleDefinitions(args []string) (*Context, []*markers.Definition, error) {
+	dirs, err := GetPackageDirectories()
+
+	if err != nil {
+		return nil, nil, errors.New("go.module not found")
+	}
+
+	var loadResult *packages.LoadResult
+	loadResult, err = packages.LoadPackages(dirs...)
+
+	if err != nil {
+		return nil, nil, errors.New("packages could not be loaded")
+	}
+
+	modDir, _ := packages.GoModDir()
+	ctx := &Context{
+		dirs:        dirs,
+		loadResult:  loadResult,
+		registry:    markers.NewRegistry(),
+		packageId:   packageName,
+		version:     processorVersion,
+		goModuleDir: modDir,
+		errors:      make([]error, 0),
+		values:      map[string]any{},
+		args:        args,
+	}
+
+	err = invokeRegistryFunctions(ctx)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var errs []error
+	importedPackages := map[string]struct{}{
+		"": {},
+	}
+
+	collector := markers.NewCollector(markers.NewRegistry())
+
+	for _, pkg := range loadResult.Packages() {
+		if pkg.IsStandardPackage() || pkg.TypesInfo == nil {
+			continue
+		}
+
+		err = ctx.registry.RegisterPackage(pkg)
+
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		importedPackages[pkg.PkgPath] = struct{}{}
+
+		var nodeMarkers map[ast.Node]markers.MarkerValues
+		nodeMarkers, err = collector.Collect(pkg)
+
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, markerValues := range nodeMarkers {
+			for _, value := range markerValues.AllMarkers(markers.ImportMarkerName) {
+				importedPackages[value.(markers.ImportMarker).PkgPath()] = struct{}{}
+			}
+		}
+	}
+
+	if len(errs) != 0 {
+		return nil, nil, markers.NewErrorList(errs)
+	}
+
+	definitions := make([]*markers.Definition, 0)
+
+	for _, definition := range ctx.registry.Definitions() {
+		if _, imported := importedPackages[definition.Package]; imported {
+			definitions = append(definitions, definition)
+		}
+	}
+
+	return ctx, definitions, nil
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 )
 
 var placeHolderRegex = regexp.MustCompile(`{(.*?)}`)
@@ -23,6 +24,8 @@ type Context struct {
 	packageId      string
 	version        string
 	errors         []error
+	warnings       []markers.Warning
+	warningsMu     sync.Mutex
 	values         map[string]any
 	args           []string
 }
@@ -211,6 +214,26 @@ func (ctx *Context) Error(err error) {
 	ctx.errors = append(ctx.errors, err)
 }
 
+// Warnings returns the warnings produced while collecting the markers.
+func (ctx *Context) Warnings() []markers.Warning {
+	ctx.warningsMu.Lock()
+	defer ctx.warningsMu.Unlock()
+	return append([]markers.Warning{}, ctx.warnings...)
+}
+
+func (ctx *Context) addWarning(warning markers.Warning) {
+	ctx.warningsMu.Lock()
+	defer ctx.warningsMu.Unlock()
+	ctx.warnings = append(ctx.warnings, warning)
+}
+
+func (ctx *Context) printWarnings() {
+	for _, warning := range ctx.Warnings() {
+		pos := warning.Position
+		log.Printf("%s (%d:%d) : warning: %s\n", warning.FileName, pos.Line, pos.Column, warning.Message)
+	}
+}
+
 func (ctx *Context) printError(err error) {
 	if err != nil {
 
@@ -0,0 +1,179 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+)
+
+const diffContextLines = 3
+
+type diffOperation struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff returns the unified diff of the given file contents. It returns
+// an empty string if the contents are equal.
+func unifiedDiff(fileName string, before, after string) string {
+	if before == after {
+		return ""
+	}
+
+	operations := diffLines(splitLines(before), splitLines(after))
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "--- a/%s\n+++ b/%s\n", fileName, fileName)
+
+	for start := 0; start < len(operations); {
+		if operations[start].kind == ' ' {
+			start++
+			continue
+		}
+
+		// a hunk contains the changes which are not separated by more than two contexts
+		hunkStart := start - diffContextLines
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+
+		hunkEnd := start
+		unchanged := 0
+
+		for index := start; index < len(operations) && unchanged <= 2*diffContextLines; index++ {
+			if operations[index].kind == ' ' {
+				unchanged++
+				continue
+			}
+
+			unchanged = 0
+			hunkEnd = index + 1
+		}
+
+		hunkEnd += diffContextLines
+		if hunkEnd > len(operations) {
+			hunkEnd = len(operations)
+		}
+
+		beforeLine, afterLine := 1, 1
+
+		for _, operation := range operations[:hunkStart] {
+			if operation.kind != '+' {
+				beforeLine++
+			}
+
+			if operation.kind != '-' {
+				afterLine++
+			}
+		}
+
+		beforeCount, afterCount := 0, 0
+
+		for _, operation := range operations[hunkStart:hunkEnd] {
+			if operation.kind != '+' {
+				beforeCount++
+			}
+
+			if operation.kind != '-' {
+				afterCount++
+			}
+		}
+
+		fmt.Fprintf(&builder, "@@ -%d,%d +%d,%d @@\n", beforeLine, beforeCount, afterLine, afterCount)
+
+		for _, operation := range operations[hunkStart:hunkEnd] {
+			builder.WriteByte(operation.kind)
+			builder.WriteString(operation.line)
+			builder.WriteByte('\n')
+		}
+
+		start = hunkEnd
+	}
+
+	return builder.String()
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines returns the shortest edit script turning the before lines into the after lines
+// by using the Myers' algorithm.
+func diffLines(before, after []string) []diffOperation {
+	maxSteps := len(before) + len(after)
+	offset := maxSteps + 1
+	frontier := make([]int, 2*maxSteps+3)
+	trace := make([][]int, 0)
+
+search:
+	for step := 0; step <= maxSteps; step++ {
+		trace = append(trace, append([]int{}, frontier...))
+
+		for diagonal := -step; diagonal <= step; diagonal += 2 {
+			var x int
+
+			if diagonal == -step || (diagonal != step && frontier[offset+diagonal-1] < frontier[offset+diagonal+1]) {
+				x = frontier[offset+diagonal+1]
+			} else {
+				x = frontier[offset+diagonal-1] + 1
+			}
+
+			y := x - diagonal
+
+			for x < len(before) && y < len(after) && before[x] == after[y] {
+				x++
+				y++
+			}
+
+			frontier[offset+diagonal] = x
+
+			if x >= len(before) && y >= len(after) {
+				break search
+			}
+		}
+	}
+
+	operations := make([]diffOperation, 0, maxSteps)
+	x, y := len(before), len(after)
+
+	for step := len(trace) - 1; step >= 0; step-- {
+		previous := trace[step]
+		diagonal := x - y
+
+		var previousDiagonal int
+
+		if diagonal == -step || (diagonal != step && previous[offset+diagonal-1] < previous[offset+diagonal+1]) {
+			previousDiagonal = diagonal + 1
+		} else {
+			previousDiagonal = diagonal - 1
+		}
+
+		previousX := previous[offset+previousDiagonal]
+		previousY := previousX - previousDiagonal
+
+		for x > previousX && y > previousY {
+			operations = append(operations, diffOperation{kind: ' ', line: before[x-1]})
+			x--
+			y--
+		}
+
+		if step > 0 {
+			if x == previousX {
+				operations = append(operations, diffOperation{kind: '+', line: after[y-1]})
+			} else {
+				operations = append(operations, diffOperation{kind: '-', line: before[x-1]})
+			}
+		}
+
+		x, y = previousX, previousY
+	}
+
+	for left, right := 0, len(operations)-1; left < right; left, right = left+1, right-1 {
+		operations[left], operations[right] = operations[right], operations[left]
+	}
+
+	return operations
+}
@@ -0,0 +1,104 @@
+package processor
+
+import (
+	"fmt"
+	"github.com/procyon-projects/marker"
+	"github.com/procyon-projects/marker/packages"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	docsFormat       string
+	docsOutputPath   string
+	docsExampleLimit int
+)
+
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Generate reference docs of the markers",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var extension string
+
+		switch docsFormat {
+		case "markdown", "md":
+			extension = ".md"
+		case "html":
+			extension = ".html"
+		default:
+			return fmt.Errorf("format '%s' is not supported, use markdown or html", docsFormat)
+		}
+
+		ctx, definitions, err := loadModuleDefinitions(args)
+
+		if err != nil {
+			return err
+		}
+
+		err = os.MkdirAll(docsOutputPath, os.ModePerm)
+
+		if err != nil {
+			return fmt.Errorf("folder %s is not created", docsOutputPath)
+		}
+
+		pkgs := make([]*packages.Package, 0)
+
+		for _, pkg := range ctx.LoadResult().Packages() {
+			if !pkg.IsStandardPackage() {
+				pkgs = append(pkgs, pkg)
+			}
+		}
+
+		for _, definition := range definitions {
+			doc := markers.NewMarkerDoc(definition, markers.FindMarkerExamples(pkgs, definition, docsExampleLimit))
+			filePath := filepath.Join(docsOutputPath, docFileName(definition)+extension)
+
+			err = writeMarkerDoc(filePath, doc, extension)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		log.Printf("docs of %d markers are generated in %s\n", len(definitions), docsOutputPath)
+		return nil
+	},
+}
+
+func init() {
+	docsCmd.Flags().StringVarP(&docsFormat, "format", "t", "markdown", "output format (markdown or html)")
+	docsCmd.Flags().StringVarP(&docsOutputPath, "output", "o", "docs", "output directory")
+	docsCmd.Flags().IntVarP(&docsExampleLimit, "examples", "e", 3, "maximum number of examples per marker")
+	rootCmd.AddCommand(docsCmd)
+}
+
+// docFileName returns the file name of the page of the given definition. The colons
+// in the marker names are not allowed in the file names on some platforms.
+func docFileName(definition *markers.Definition) string {
+	name := strings.ReplaceAll(definition.Name, ":", "-")
+
+	if definition.Package == "" {
+		return name
+	}
+
+	return strings.ReplaceAll(definition.Package, "/", "_") + "_" + name
+}
+
+func writeMarkerDoc(filePath string, doc markers.MarkerDoc, extension string) error {
+	file, err := os.Create(filePath)
+
+	if err != nil {
+		return fmt.Errorf("%s is not created", filePath)
+	}
+
+	defer file.Close()
+
+	if extension == ".html" {
+		return doc.WriteHTML(file)
+	}
+
+	return doc.WriteMarkdown(file)
+}
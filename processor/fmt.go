@@ -0,0 +1,126 @@
+package processor
+
+import (
+	"fmt"
+	"github.com/procyon-projects/marker"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	fmtWrite bool
+	fmtWidth int
+)
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt",
+	Short: "Format the marker comments in the module",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, _, err := loadModuleDefinitions(args)
+
+		if err != nil {
+			return err
+		}
+
+		options := markers.FormatOptions{
+			Width: fmtWidth,
+		}
+
+		formattedFiles := 0
+
+		for _, fileName := range moduleFiles(ctx) {
+			var src []byte
+			src, err = os.ReadFile(fileName)
+
+			if err != nil {
+				return fmt.Errorf("%s could not be read", fileName)
+			}
+
+			var formatted []byte
+			formatted, err = markers.FormatSource(ctx.Registry(), src, options)
+
+			if err != nil {
+				return fmt.Errorf("%s could not be formatted: %w", fileName, err)
+			}
+
+			if string(formatted) == string(src) {
+				continue
+			}
+
+			formattedFiles++
+
+			if !fmtWrite {
+				fmt.Print(unifiedDiff(relativePath(ctx, fileName), string(src), string(formatted)))
+				continue
+			}
+
+			err = writeFile(fileName, formatted)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if fmtWrite {
+			log.Printf("%d files are formatted\n", formattedFiles)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	fmtCmd.Flags().BoolVarP(&fmtWrite, "write", "w", false, "write the result to the files instead of printing the diff")
+	fmtCmd.Flags().IntVarP(&fmtWidth, "width", "l", markers.DefaultFormatWidth, "maximum line width of the marker comments")
+	rootCmd.AddCommand(fmtCmd)
+}
+
+// moduleFiles returns the go files of the packages in the go module.
+func moduleFiles(ctx *Context) []string {
+	fileNames := make([]string, 0)
+
+	for _, pkg := range ctx.LoadResult().Packages() {
+		if pkg.IsStandardPackage() {
+			continue
+		}
+
+		for _, fileName := range pkg.GoFiles {
+			if strings.HasPrefix(fileName, ctx.goModuleDir+string(filepath.Separator)) {
+				fileNames = append(fileNames, fileName)
+			}
+		}
+	}
+
+	return fileNames
+}
+
+// relativePath returns the path of the given file relative to the go module directory.
+func relativePath(ctx *Context, fileName string) string {
+	relative, err := filepath.Rel(ctx.goModuleDir, fileName)
+
+	if err != nil {
+		return fileName
+	}
+
+	return filepath.ToSlash(relative)
+}
+
+// writeFile writes the data to the given file by keeping its permissions.
+func writeFile(fileName string, data []byte) error {
+	info, err := os.Stat(fileName)
+
+	if err != nil {
+		return fmt.Errorf("%s could not be read", fileName)
+	}
+
+	err = os.WriteFile(fileName, data, info.Mode().Perm())
+
+	if err != nil {
+		return fmt.Errorf("%s could not be written", fileName)
+	}
+
+	return nil
+}
@@ -9,7 +9,11 @@ import (
 	"path"
 )
 
-var configFilePath string
+var (
+	configFilePath string
+	cacheDir       string
+	noCache        bool
+)
 
 var generateCmd = &cobra.Command{
 	Use:   "generate",
@@ -79,18 +83,45 @@ var generateCmd = &cobra.Command{
 		}
 
 		collector := markers.NewCollector(registry)
+		collector.WarningCallback = ctx.addWarning
 		ctx.collector = collector
 
+		if !noCache {
+			collector.Cache, err = getCollectionCache(cacheDir)
+
+			if err != nil {
+				return err
+			}
+		}
+
 		generateCallback := getGenerateCommandCallback()
 		if generateCallback != nil {
 			generateCallback(ctx)
 		}
 
+		ctx.printWarnings()
+
 		return nil
 	},
 }
 
 func init() {
 	generateCmd.Flags().StringVarP(&configFilePath, "file", "f", "", "config file path")
+	generateCmd.Flags().StringVar(&cacheDir, "cache-dir", "", "directory of the collection cache (default is $MARKERCACHE or the user cache directory)")
+	generateCmd.Flags().BoolVar(&noCache, "no-cache", false, "collect the markers without using the collection cache")
 	rootCmd.AddCommand(generateCmd)
 }
+
+func getCollectionCache(dir string) (*markers.CollectionCache, error) {
+	var err error
+
+	if dir == "" {
+		dir, err = markers.DefaultCollectionCacheDir()
+
+		if err != nil {
+			return nil, errors.New("collection cache directory could not be determined, use --cache-dir or --no-cache")
+		}
+	}
+
+	return markers.NewCollectionCache(dir)
+}
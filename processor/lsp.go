@@ -0,0 +1,31 @@
+package processor
+
+import (
+	"github.com/procyon-projects/marker"
+	"github.com/procyon-projects/marker/lsp"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run the language server for the marker comments over stdio",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		registry := markers.NewRegistry()
+		ctx, _, err := loadModuleDefinitions(args)
+
+		if err != nil {
+			// the logs are written to stderr, stdout is used by the protocol
+			log.Printf("only the reserved markers are available: %v", err)
+		} else {
+			registry = ctx.Registry()
+		}
+
+		return lsp.NewServer(registry).Serve(os.Stdin, os.Stdout)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
@@ -0,0 +1,137 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"github.com/procyon-projects/marker"
+	"github.com/procyon-projects/marker/packages"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"log"
+	"os"
+)
+
+// MigrationRules is the content of the migration rules file.
+type MigrationRules struct {
+	Rules []markers.MigrationRule `yaml:"rules"`
+}
+
+var (
+	migrateRulesPath string
+	migrateDryRun    bool
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the marker comments in the module by using the rules file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rules, err := getMigrationRules(migrateRulesPath)
+
+		if err != nil {
+			return err
+		}
+
+		var dirs []string
+		dirs, err = GetPackageDirectories()
+
+		if err != nil {
+			return errors.New("go.module not found")
+		}
+
+		var loadResult *packages.LoadResult
+		loadResult, err = packages.LoadPackages(dirs...)
+
+		if err != nil {
+			return errors.New("packages could not be loaded")
+		}
+
+		modDir, _ := packages.GoModDir()
+		ctx := &Context{
+			dirs:        dirs,
+			loadResult:  loadResult,
+			goModuleDir: modDir,
+		}
+
+		migratedFiles := 0
+		var problems []markers.MigrationProblem
+
+		for _, fileName := range moduleFiles(ctx) {
+			var src []byte
+			src, err = os.ReadFile(fileName)
+
+			if err != nil {
+				return fmt.Errorf("%s could not be read", fileName)
+			}
+
+			var migrated []byte
+			var fileProblems []markers.MigrationProblem
+			migrated, fileProblems, err = markers.MigrateSource(fileName, src, rules)
+
+			if err != nil {
+				return fmt.Errorf("%s could not be migrated: %w", fileName, err)
+			}
+
+			problems = append(problems, fileProblems...)
+
+			if string(migrated) == string(src) {
+				continue
+			}
+
+			migratedFiles++
+
+			if migrateDryRun {
+				fmt.Print(unifiedDiff(relativePath(ctx, fileName), string(src), string(migrated)))
+				continue
+			}
+
+			err = writeFile(fileName, migrated)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, problem := range problems {
+			log.Println(problem)
+		}
+
+		if len(problems) != 0 {
+			log.Printf("%d marker occurrences could not be migrated\n", len(problems))
+		}
+
+		if !migrateDryRun {
+			log.Printf("%d files are migrated\n", migratedFiles)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	migrateCmd.Flags().StringVarP(&migrateRulesPath, "rules", "r", "marker.migration.yaml", "path of the migration rules file")
+	migrateCmd.Flags().BoolVarP(&migrateDryRun, "dry-run", "d", false, "print the diff instead of writing the files")
+	rootCmd.AddCommand(migrateCmd)
+}
+
+func getMigrationRules(rulesFilePath string) ([]markers.MigrationRule, error) {
+	data, err := os.ReadFile(rulesFilePath)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s not found", rulesFilePath)
+	}
+
+	rules := &MigrationRules{}
+	err = yaml.Unmarshal(data, rules)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s could not be parsed: %w", rulesFilePath, err)
+	}
+
+	err = markers.ValidateMigrationRules(rules.Rules)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s is not valid: %w", rulesFilePath, err)
+	}
+
+	return rules.Rules, nil
+}
@@ -0,0 +1,131 @@
+package processor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/procyon-projects/marker"
+	"github.com/procyon-projects/marker/packages"
+	"github.com/procyon-projects/marker/visitor"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var queryFormat string
+
+var queryCmd = &cobra.Command{
+	Use:   "query [query]",
+	Short: "Print the code elements matching a marker query",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("query is required")
+		}
+
+		if queryFormat != "text" && queryFormat != "json" {
+			return fmt.Errorf("format '%s' is not supported, use text or json", queryFormat)
+		}
+
+		query, err := visitor.ParseQuery(args[0])
+
+		if err != nil {
+			return err
+		}
+
+		ctx, _, err := loadModuleDefinitions(nil)
+
+		if err != nil {
+			return err
+		}
+
+		// the matches are printed even if some markers cannot be collected, the elements
+		// having such markers are matched without them.
+		matches, selectErr := query.Select(markers.NewCollector(ctx.Registry()), modulePackages(ctx))
+
+		if queryFormat == "json" {
+			err = printQueryMatchesAsJSON(ctx, matches)
+		} else {
+			err = printQueryMatchesAsText(ctx, matches)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if selectErr != nil {
+			ctx.printError(selectErr)
+			cmd.SilenceUsage = true
+			return errors.New("markers could not be collected")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	queryCmd.Flags().StringVarP(&queryFormat, "format", "t", "text", "output format (text or json)")
+	rootCmd.AddCommand(queryCmd)
+}
+
+type queryMatch struct {
+	Kind    string   `json:"kind"`
+	Name    string   `json:"name"`
+	File    string   `json:"file"`
+	Line    int      `json:"line"`
+	Column  int      `json:"column"`
+	Markers []string `json:"markers"`
+}
+
+func printQueryMatchesAsText(ctx *Context, matches []visitor.Match) error {
+	for _, match := range matches {
+		position := match.Element.Position()
+		_, err := fmt.Fprintf(os.Stdout, "%s:%d:%d: %s %s\n", relativePath(ctx, match.Element.File().Path()), position.Line, position.Column, match.Kind, match.Element.Name())
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func printQueryMatchesAsJSON(ctx *Context, matches []visitor.Match) error {
+	output := make([]queryMatch, 0, len(matches))
+
+	for _, match := range matches {
+		position := match.Element.Position()
+		output = append(output, queryMatch{
+			Kind:    match.Kind,
+			Name:    match.Element.Name(),
+			File:    relativePath(ctx, match.Element.File().Path()),
+			Line:    position.Line,
+			Column:  position.Column,
+			Markers: match.Element.Markers().Names(),
+		})
+	}
+
+	jsonText, _ := json.MarshalIndent(output, "", "\t")
+	_, err := fmt.Fprintln(os.Stdout, string(jsonText))
+	return err
+}
+
+// modulePackages returns the loaded packages having files in the go module directory.
+func modulePackages(ctx *Context) []*packages.Package {
+	pkgs := make([]*packages.Package, 0)
+
+	for _, pkg := range ctx.LoadResult().Packages() {
+		if pkg.IsStandardPackage() || pkg.TypesInfo == nil {
+			continue
+		}
+
+		for _, fileName := range pkg.GoFiles {
+			if strings.HasPrefix(fileName, ctx.goModuleDir+string(filepath.Separator)) {
+				pkgs = append(pkgs, pkg)
+				break
+			}
+		}
+	}
+
+	return pkgs
+}
@@ -0,0 +1,54 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/procyon-projects/marker"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var schemaMarkerName string
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the catalog of the markers as JSON",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, definitions, err := loadModuleDefinitions(args)
+
+		if err != nil {
+			return err
+		}
+
+		catalog := markers.NewCatalog(definitions)
+		var output any = catalog
+
+		if schemaMarkerName != "" {
+			output, err = findMarkerSchema(catalog, schemaMarkerName)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		jsonText, _ := json.MarshalIndent(output, "", "\t")
+		_, err = fmt.Fprintln(os.Stdout, string(jsonText))
+		return err
+	},
+}
+
+func init() {
+	schemaCmd.Flags().StringVarP(&schemaMarkerName, "marker", "m", "", "print the JSON Schema of the marker with the given name")
+	rootCmd.AddCommand(schemaCmd)
+}
+
+// findMarkerSchema returns the JSON Schema of the marker with the given name in the catalog.
+func findMarkerSchema(catalog markers.Catalog, name string) (*markers.JSONSchema, error) {
+	for _, catalogMarker := range catalog.Markers {
+		if catalogMarker.Name == name {
+			return catalogMarker.Schema, nil
+		}
+	}
+
+	return nil, fmt.Errorf("marker '%s' not found", name)
+}
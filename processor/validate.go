@@ -78,6 +78,7 @@ var validateCmd = &cobra.Command{
 		}
 
 		collector := markers.NewCollector(registry)
+		collector.WarningCallback = ctx.addWarning
 		ctx.collector = collector
 
 		validateCallback := getValidateCommandCallback()
@@ -85,6 +86,8 @@ var validateCmd = &cobra.Command{
 			validateCallback(ctx)
 		}
 
+		ctx.printWarnings()
+
 		return nil
 	},
 }
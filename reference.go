@@ -0,0 +1,273 @@
+package markers
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+)
+
+// TypeReference is a reference to a Go type such as Service or time.Time.
+// The arguments of this type are resolved by the collector.
+type TypeReference struct {
+	// Package is the name of the imported package, it is empty for the local types.
+	Package string
+	Name    string
+	// PkgPath and Object are available after the reference is resolved.
+	PkgPath string
+	Object  types.Object
+
+	offset int
+}
+
+func (reference TypeReference) String() string {
+	return qualifiedName(reference.Package, reference.Name)
+}
+
+// FuncReference is a reference to a Go function such as NewService or http.NewRequest.
+// The arguments of this type are resolved by the collector.
+type FuncReference struct {
+	// Package is the name of the imported package, it is empty for the local functions.
+	Package string
+	Name    string
+	// PkgPath and Object are available after the reference is resolved.
+	PkgPath string
+	Object  types.Object
+
+	offset int
+}
+
+func (reference FuncReference) String() string {
+	return qualifiedName(reference.Package, reference.Name)
+}
+
+var (
+	typeReferenceType = reflect.TypeOf(TypeReference{})
+	funcReferenceType = reflect.TypeOf(FuncReference{})
+	dynamicValueType  = reflect.TypeOf(&DynamicValue{})
+)
+
+func qualifiedName(pkg, name string) string {
+	if pkg == "" {
+		return name
+	}
+
+	return pkg + "." + name
+}
+
+func (typeInfo ArgumentTypeInfo) parseReference(scanner *Scanner, out reflect.Value) error {
+	if scanner == nil {
+		return errors.New("scanner cannot be nil")
+	}
+
+	if !scanner.Expect(Identifier, "Identifier") {
+		return fmt.Errorf("expected identifier, got %q", scanner.Token())
+	}
+
+	offset := scanner.TokenPosition()
+	pkg := ""
+	name := scanner.Token()
+
+	if scanner.Peek() == '.' {
+		scanner.Scan()
+
+		if !scanner.Expect(Identifier, "Identifier") {
+			return fmt.Errorf("expected identifier, got %q", scanner.Token())
+		}
+
+		pkg = name
+		name = scanner.Token()
+	}
+
+	if typeInfo.ActualType == GoFuncType {
+		typeInfo.setValue(out, reflect.ValueOf(FuncReference{Package: pkg, Name: name, offset: offset}))
+	} else {
+		typeInfo.setValue(out, reflect.ValueOf(TypeReference{Package: pkg, Name: name, offset: offset}))
+	}
+
+	return nil
+}
+
+// hasReferences reports whether the type contains a type or function reference.
+func (typeInfo ArgumentTypeInfo) hasReferences() bool {
+	switch typeInfo.ActualType {
+	case GoType, GoFuncType:
+		return true
+	case SliceType, MapType:
+		return typeInfo.ItemType != nil && typeInfo.ItemType.hasReferences()
+	case StructType:
+		for _, argument := range typeInfo.Fields {
+			if argument.TypeInfo.hasReferences() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// resolveReferences resolves the type and function references in the given marker value
+// by using the scope of the file the marker belongs to. It returns the resolved value.
+func resolveReferences(value any, pkg *types.Package, info *types.Info, file *ast.File) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	copied := reflect.New(reflect.TypeOf(value)).Elem()
+	copied.Set(reflect.ValueOf(value))
+
+	resolver := &referenceResolver{
+		pkg:  pkg,
+		info: info,
+		file: file,
+	}
+	resolver.resolve(copied)
+
+	return copied.Interface(), NewErrorList(resolver.errs)
+}
+
+type referenceResolver struct {
+	pkg  *types.Package
+	info *types.Info
+	file *ast.File
+	errs []error
+}
+
+func (resolver *referenceResolver) resolve(value reflect.Value) {
+	switch value.Type() {
+	case typeReferenceType:
+		reference := value.Addr().Interface().(*TypeReference)
+		object, err := resolver.lookup(reference.Package, reference.Name, reference.offset)
+
+		if err != nil {
+			resolver.errs = append(resolver.errs, err)
+			return
+		}
+
+		if _, isTypeName := object.(*types.TypeName); !isTypeName {
+			resolver.errs = append(resolver.errs, resolver.error(reference.String(), fmt.Sprintf("'%s' is not a type", reference.Name), reference.offset))
+			return
+		}
+
+		reference.Object = object
+		reference.PkgPath = packagePath(object)
+		return
+	case funcReferenceType:
+		reference := value.Addr().Interface().(*FuncReference)
+		object, err := resolver.lookup(reference.Package, reference.Name, reference.offset)
+
+		if err != nil {
+			resolver.errs = append(resolver.errs, err)
+			return
+		}
+
+		if _, isFunc := object.(*types.Func); !isFunc {
+			resolver.errs = append(resolver.errs, resolver.error(reference.String(), fmt.Sprintf("'%s' is not a function", reference.Name), reference.offset))
+			return
+		}
+
+		reference.Object = object
+		reference.PkgPath = packagePath(object)
+		return
+	case dynamicValueType:
+		if value.IsNil() {
+			return
+		}
+
+		dynamicValue := value.Interface().(*DynamicValue)
+
+		for index, argument := range dynamicValue.arguments {
+			if argument.Value == nil {
+				continue
+			}
+
+			item := reflect.New(reflect.TypeOf(argument.Value)).Elem()
+			item.Set(reflect.ValueOf(argument.Value))
+			resolver.resolve(item)
+			dynamicValue.arguments[index].Value = item.Interface()
+		}
+
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			resolver.resolve(value.Elem())
+		}
+	case reflect.Struct:
+		for index := 0; index < value.NumField(); index++ {
+			if field := value.Field(index); field.CanSet() {
+				resolver.resolve(field)
+			}
+		}
+	case reflect.Slice:
+		for index := 0; index < value.Len(); index++ {
+			resolver.resolve(value.Index(index))
+		}
+	case reflect.Map:
+		iterator := value.MapRange()
+
+		for iterator.Next() {
+			item := reflect.New(iterator.Value().Type()).Elem()
+			item.Set(iterator.Value())
+			resolver.resolve(item)
+			value.SetMapIndex(iterator.Key(), item)
+		}
+	}
+}
+
+func (resolver *referenceResolver) lookup(pkg, name string, offset int) (types.Object, error) {
+	reference := qualifiedName(pkg, name)
+
+	if resolver.pkg == nil || resolver.info == nil {
+		return nil, resolver.error(reference, "type information is not available", offset)
+	}
+
+	scope := resolver.pkg.Scope()
+
+	if fileScope, ok := resolver.info.Scopes[resolver.file]; ok {
+		scope = fileScope
+	}
+
+	if pkg == "" {
+		_, object := scope.LookupParent(name, 0)
+
+		if object == nil {
+			return nil, resolver.error(reference, fmt.Sprintf("'%s' is not declared", name), offset)
+		}
+
+		return object, nil
+	}
+
+	pkgName, isPkgName := scope.Lookup(pkg).(*types.PkgName)
+
+	if !isPkgName {
+		return nil, resolver.error(reference, fmt.Sprintf("package '%s' is not imported", pkg), offset)
+	}
+
+	object := pkgName.Imported().Scope().Lookup(name)
+
+	if object == nil || !object.Exported() {
+		return nil, resolver.error(reference, fmt.Sprintf("'%s' is not declared by package '%s'", name, pkgName.Imported().Path()), offset)
+	}
+
+	return object, nil
+}
+
+func (resolver *referenceResolver) error(reference, reason string, offset int) error {
+	return ReferenceError{
+		Reference: reference,
+		Reason:    reason,
+		Offset:    offset,
+	}
+}
+
+func packagePath(object types.Object) string {
+	if object.Pkg() == nil {
+		return ""
+	}
+
+	return object.Pkg().Path()
+}
@@ -3,6 +3,8 @@ package markers
 import (
 	"errors"
 	"fmt"
+	"github.com/procyon-projects/marker/packages"
+	"sort"
 	"sync"
 )
 
@@ -12,6 +14,7 @@ type DefinitionMap map[string]*Definition
 type Registry struct {
 	packageMap map[string]DefinitionMap
 	mu         sync.RWMutex
+	once       sync.Once
 }
 
 // NewRegistry returns a new registry to register the markers.
@@ -21,13 +24,22 @@ func NewRegistry() *Registry {
 	return registry
 }
 
-// initialize initializes the registry when needed.
+// initialize initializes the registry once, so that the registry can be looked up concurrently.
 func (registry *Registry) initialize() {
+	registry.once.Do(registry.registerBuiltinDefinitions)
+}
+
+func (registry *Registry) registerBuiltinDefinitions() {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
 	if registry.packageMap == nil {
 		registry.packageMap = make(map[string]DefinitionMap)
 		registry.packageMap[""] = make(DefinitionMap)
 	}
-	registry.packageMap[""][ImportMarkerName], _ = MakeDefinition(ImportMarkerName, "", PackageLevel, &ImportMarker{})
+	importMarker, _ := MakeDefinition(ImportMarkerName, "", PackageLevel, &ImportMarker{})
+	importMarker.Repeatable = true
+	registry.packageMap[""][ImportMarkerName] = importMarker
 
 	overrideMarker, _ := MakeDefinition(OverrideMarkerName, "", StructMethodLevel, &OverrideMarker{})
 	overrideMarker.Output.SyntaxFree = true
@@ -39,7 +51,9 @@ func (registry *Registry) initialize() {
 
 	registry.packageMap[""][DefinitionMarkerName], _ = MakeDefinition(DefinitionMarkerName, "", StructTypeLevel, &DefinitionMarker{})
 	registry.packageMap[""][DefinitionParameterMarkerName], _ = MakeDefinition(DefinitionParameterMarkerName, "", FieldLevel, &DefinitionParameterMarker{})
-	registry.packageMap[""][DefinitionEnumMarkerName], _ = MakeDefinition(DefinitionEnumMarkerName, "", FieldLevel, &DefinitionEnumMarker{})
+	definitionEnumMarker, _ := MakeDefinition(DefinitionEnumMarkerName, "", FieldLevel, &DefinitionEnumMarker{})
+	definitionEnumMarker.Repeatable = true
+	registry.packageMap[""][DefinitionEnumMarkerName] = definitionEnumMarker
 }
 
 // Register registers a new marker with the given name, target level, and output type.
@@ -53,6 +67,25 @@ func (registry *Registry) Register(name, pkg string, level TargetLevel, output a
 	return registry.RegisterWithDefinition(def)
 }
 
+// RegisterPackage registers the marker definitions declared in the source of the given package.
+func (registry *Registry) RegisterPackage(pkg *packages.Package) error {
+	definitions, err := LoadDefinitions(pkg)
+
+	if err != nil {
+		return err
+	}
+
+	for _, definition := range definitions {
+		err = registry.RegisterWithDefinition(definition)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // RegisterWithDefinition registers a new marker with the given definition.
 func (registry *Registry) RegisterWithDefinition(definition *Definition) error {
 	registry.mu.Lock()
@@ -100,3 +133,35 @@ func (registry *Registry) Lookup(name, pkg string, targetLevel TargetLevel) (*De
 
 	return nil, false
 }
+
+// Definitions returns the registered definitions sorted by their packages and names.
+func (registry *Registry) Definitions() []*Definition {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	pkgs := make([]string, 0, len(registry.packageMap))
+
+	for pkg := range registry.packageMap {
+		pkgs = append(pkgs, pkg)
+	}
+
+	sort.Strings(pkgs)
+	definitions := make([]*Definition, 0)
+
+	for _, pkg := range pkgs {
+		definitionMap := registry.packageMap[pkg]
+		names := make([]string, 0, len(definitionMap))
+
+		for name := range definitionMap {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			definitions = append(definitions, definitionMap[name])
+		}
+	}
+
+	return definitions
+}
@@ -21,6 +21,7 @@ type Scanner struct {
 	character          rune
 
 	errorCount    int
+	suggestion    string
 	ErrorCallback func(scanner *Scanner, message string)
 }
 
@@ -55,6 +56,28 @@ func (scanner *Scanner) AddError(message string) {
 	}
 }
 
+// AddSuggestedError adds an error which can be fixed by replacing the current token with the suggestion.
+func (scanner *Scanner) AddSuggestedError(message, suggestion string) {
+	scanner.suggestion = suggestion
+	scanner.AddError(message)
+	scanner.suggestion = ""
+}
+
+// newError returns a ScannerError for the given message at the current token.
+func (scanner *Scanner) newError(message string) ScannerError {
+	err := ScannerError{
+		Message: message,
+		Offset:  scanner.TokenPosition(),
+	}
+
+	if scanner.suggestion != "" {
+		err.Suggestion = scanner.suggestion
+		err.Length = len(scanner.Token())
+	}
+
+	return err
+}
+
 func (scanner *Scanner) Peek() rune {
 	if scanner.character == Identifier {
 		scanner.character = scanner.Next()
@@ -67,7 +90,12 @@ func (scanner *Scanner) Expect(expected rune, description string) bool {
 	token := scanner.Scan()
 
 	if token != expected {
-		//scanner.AddError(fmt.Sprintf("got %q; want %s", scanner.Token(), description))
+		if token == EOF {
+			scanner.AddError(fmt.Sprintf("got EOF; want %s", description))
+		} else {
+			scanner.AddError(fmt.Sprintf("got %q; want %s", scanner.Token(), description))
+		}
+
 		return false
 	}
 
@@ -123,6 +151,8 @@ func (scanner *Scanner) Scan() rune {
 		token = IntegerValue
 		character = scanner.ScanNumber()
 	} else if character == EOF {
+		scanner.tokenStartPosition = scanner.SourceLength()
+		scanner.tokenEndPosition = scanner.SourceLength()
 		return EOF
 	} else if character == '"' {
 		token = StringValue
@@ -184,6 +214,16 @@ func (scanner *Scanner) ScanString(quote rune) (len int) {
 			return
 		}
 
+		// the escaped characters cannot end the interpreted strings
+		if character == '\\' && quote != '`' {
+			character = scanner.Next()
+			len++
+
+			if character == '\n' || character < 0 {
+				continue
+			}
+		}
+
 		character = scanner.Next()
 		len++
 	}
@@ -194,6 +234,15 @@ func (scanner *Scanner) ScanString(quote rune) (len int) {
 	return
 }
 
+// TokenPosition returns the offset of the current token within the source.
+func (scanner *Scanner) TokenPosition() int {
+	if scanner.tokenStartPosition < 0 {
+		return 0
+	}
+
+	return scanner.tokenStartPosition
+}
+
 func (scanner *Scanner) Token() string {
 	if scanner.tokenStartPosition < 0 {
 		return ""
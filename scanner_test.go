@@ -100,6 +100,10 @@ func TestScanner_ScanString(t *testing.T) {
 
 	current = scanner.Scan()
 	assert.Equal(t, EOF, int(current))
+
+	scanner = NewScanner(`"say \"hi\"\\" x`)
+	assert.Equal(t, StringValue, int(scanner.Scan()))
+	assert.Equal(t, `"say \"hi\"\\"`, scanner.Token())
 }
 
 func TestScanner_AllScans(t *testing.T) {
@@ -147,3 +151,23 @@ func TestScanner_AllScans(t *testing.T) {
 	current = scanner.Scan()
 	assert.Equal(t, EOF, int(current))
 }
+
+func TestScanner_ExpectShouldAddError(t *testing.T) {
+	var messages []string
+	var offsets []int
+
+	scanner := NewScanner("key=123")
+	scanner.ErrorCallback = func(scanner *Scanner, message string) {
+		messages = append(messages, message)
+		offsets = append(offsets, scanner.TokenPosition())
+	}
+
+	assert.True(t, scanner.Expect(Identifier, "Identifier"))
+	assert.False(t, scanner.Expect(',', "Comma ','"))
+	assert.True(t, scanner.Expect(IntegerValue, "Integer"))
+	assert.False(t, scanner.Expect(',', "Comma ','"))
+
+	assert.Equal(t, 2, scanner.ErrorCount())
+	assert.Equal(t, []string{"got \"=\"; want Comma ','", "got EOF; want Comma ','"}, messages)
+	assert.Equal(t, []int{3, 7}, offsets)
+}
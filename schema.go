@@ -0,0 +1,139 @@
+package markers
+
+import (
+	"fmt"
+	"sort"
+)
+
+// JSONSchemaDialect is the JSON Schema version the marker schemas conform to.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// referencePattern matches the type and function references such as Service or http.NewRequest.
+const referencePattern = `^([A-Za-z_][A-Za-z0-9_]*\.)?[A-Za-z_][A-Za-z0-9_]*$`
+
+// JSONSchema is a JSON Schema describing the arguments of a marker as a JSON object.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+	Deprecated           bool                   `json:"deprecated,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"`
+}
+
+// JSONSchema returns the JSON Schema of the definition. The arguments of the marker are
+// the properties of the schema, the unknown arguments are not allowed unless the definition
+// allows them.
+func (definition *Definition) JSONSchema() *JSONSchema {
+	schema := objectSchema(definition.arguments())
+	schema.Schema = JSONSchemaDialect
+	schema.ID = definitionSchemaID(definition)
+	schema.Title = definition.Name
+	schema.Description = definition.Description
+	schema.Deprecated = definition.Deprecated
+
+	if definition.AllowUnknownArguments {
+		schema.AdditionalProperties = nil
+	}
+
+	return schema
+}
+
+// definitionSchemaID returns the identifier of the schema of the given definition.
+func definitionSchemaID(definition *Definition) string {
+	if definition.Package == "" {
+		return fmt.Sprintf("marker:%s", definition.Name)
+	}
+
+	return fmt.Sprintf("marker:%s/%s", definition.Package, definition.Name)
+}
+
+func objectSchema(arguments map[string]Argument) *JSONSchema {
+	schema := &JSONSchema{
+		Type:                 "object",
+		Properties:           make(map[string]*JSONSchema, len(arguments)),
+		AdditionalProperties: false,
+	}
+
+	for _, name := range sortedArgumentNames(arguments) {
+		argument := arguments[name]
+		property := typeSchema(argument.TypeInfo)
+		property.Description = argument.Description
+		property.Default = catalogValue(argument.Default)
+		property.Deprecated = argument.Deprecated
+		schema.Properties[name] = property
+
+		if argument.Required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+func typeSchema(typeInfo ArgumentTypeInfo) *JSONSchema {
+	switch typeInfo.ActualType {
+	case BoolType:
+		return &JSONSchema{Type: "boolean"}
+	case SignedIntegerType:
+		return &JSONSchema{Type: "integer"}
+	case UnsignedIntegerType:
+		minimum := 0
+		return &JSONSchema{Type: "integer", Minimum: &minimum}
+	case FloatType:
+		return &JSONSchema{Type: "number"}
+	case StringType, RawType:
+		schema := &JSONSchema{Type: "string"}
+
+		if len(typeInfo.Enum) != 0 {
+			values := make([]string, 0, len(typeInfo.Enum))
+
+			for value := range typeInfo.Enum {
+				values = append(values, value)
+			}
+
+			sort.Strings(values)
+
+			for _, value := range values {
+				schema.Enum = append(schema.Enum, value)
+			}
+		}
+
+		return schema
+	case DurationType:
+		return &JSONSchema{Type: "string", Format: "go-duration"}
+	case TimeType:
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	case GoType, GoFuncType:
+		return &JSONSchema{Type: "string", Pattern: referencePattern}
+	case SliceType:
+		schema := &JSONSchema{Type: "array"}
+
+		if typeInfo.ItemType != nil {
+			schema.Items = typeSchema(*typeInfo.ItemType)
+		}
+
+		return schema
+	case MapType:
+		schema := &JSONSchema{Type: "object"}
+
+		if typeInfo.ItemType != nil {
+			schema.AdditionalProperties = typeSchema(*typeInfo.ItemType)
+		}
+
+		return schema
+	case StructType:
+		return objectSchema(typeInfo.Fields)
+	}
+
+	return &JSONSchema{}
+}
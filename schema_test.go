@@ -0,0 +1,72 @@
+package markers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type schemaMarker struct {
+	Name     string            `parameter:"Name" required:"true" description:"name of the bean"`
+	Scope    string            `parameter:"Scope" enum:"singleton,prototype" default:"singleton"`
+	Priority uint              `parameter:"Priority"`
+	Ratio    float64           `parameter:"Ratio"`
+	Timeout  time.Duration     `parameter:"Timeout" default:"5s"`
+	Since    time.Time         `parameter:"Since"`
+	Type     TypeReference     `parameter:"Type"`
+	Labels   map[string]bool   `parameter:"Labels"`
+	Column   indexColumn       `parameter:"Column"`
+	Legacy   bool              `parameter:"Legacy" deprecated:"use Scope"`
+	Any      any               `parameter:"Any"`
+	Headers  map[string]string `parameter:"Headers"`
+}
+
+func TestDefinition_JSONSchema(t *testing.T) {
+	definition, err := MakeDefinition("test:bean", "anyPkg", StructTypeLevel, &schemaMarker{})
+	assert.NoError(t, err)
+	definition.Description = "declares a bean"
+
+	minimum := 0
+	assert.Equal(t, &JSONSchema{
+		Schema:      JSONSchemaDialect,
+		ID:          "marker:anyPkg/test:bean",
+		Title:       "test:bean",
+		Description: "declares a bean",
+		Type:        "object",
+		Properties: map[string]*JSONSchema{
+			"Name":     {Type: "string", Description: "name of the bean"},
+			"Scope":    {Type: "string", Enum: []any{"prototype", "singleton"}, Default: "singleton"},
+			"Priority": {Type: "integer", Minimum: &minimum},
+			"Ratio":    {Type: "number"},
+			"Timeout":  {Type: "string", Format: "go-duration", Default: "5s"},
+			"Since":    {Type: "string", Format: "date-time"},
+			"Type":     {Type: "string", Pattern: referencePattern},
+			"Labels":   {Type: "object", AdditionalProperties: &JSONSchema{Type: "boolean"}},
+			"Column": {
+				Type: "object",
+				Properties: map[string]*JSONSchema{
+					"Name":  {Type: "string"},
+					"Order": {Type: "string", Enum: []any{"asc", "desc"}, Default: "asc"},
+					"Size":  {Type: "integer"},
+				},
+				Required:             []string{"Name"},
+				AdditionalProperties: false,
+			},
+			"Legacy":  {Type: "boolean", Deprecated: true},
+			"Any":     {},
+			"Headers": {Type: "object", AdditionalProperties: &JSONSchema{Type: "string"}},
+		},
+		Required:             []string{"Name"},
+		AdditionalProperties: false,
+	}, definition.JSONSchema())
+}
+
+func TestDefinition_JSONSchemaShouldAllowUnknownArguments(t *testing.T) {
+	definition, err := MakeDefinition("deprecated", "", FieldLevel, &DeprecatedMarker{})
+	assert.NoError(t, err)
+	definition.AllowUnknownArguments = true
+
+	schema := definition.JSONSchema()
+	assert.Equal(t, "marker:deprecated", schema.ID)
+	assert.Nil(t, schema.AdditionalProperties)
+}
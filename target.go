@@ -1,6 +1,9 @@
 package markers
 
-import "go/ast"
+import (
+	"fmt"
+	"go/ast"
+)
 
 // TargetLevel describes which kind of nodes a given marker are associated with.
 type TargetLevel int
@@ -63,3 +66,52 @@ func FindTargetLevelFromNode(node ast.Node) TargetLevel {
 
 	return InvalidLevel
 }
+
+// targetLevelNames maps the target names used by the marker definitions to the target levels.
+var targetLevelNames = map[string]TargetLevel{
+	"PACKAGE_LEVEL":          PackageLevel,
+	"STRUCT_TYPE_LEVEL":      StructTypeLevel,
+	"INTERFACE_TYPE_LEVEL":   InterfaceTypeLevel,
+	"FIELD_LEVEL":            FieldLevel,
+	"FUNCTION_LEVEL":         FunctionLevel,
+	"STRUCT_METHOD_LEVEL":    StructMethodLevel,
+	"INTERFACE_METHOD_LEVEL": InterfaceMethodLevel,
+}
+
+// TargetLevelFromNames returns the target level combining the levels with the given names
+// such as FIELD_LEVEL and FUNCTION_LEVEL.
+func TargetLevelFromNames(names []string) (TargetLevel, error) {
+	var level TargetLevel
+
+	for _, name := range names {
+		targetLevel, ok := targetLevelNames[name]
+
+		if !ok {
+			return 0, fmt.Errorf("target '%s' is not valid", name)
+		}
+
+		level |= targetLevel
+	}
+
+	return level, nil
+}
+
+// Names returns the names of the target levels combined in the level in the order of the levels.
+func (targetLevel TargetLevel) Names() []string {
+	names := make([]string, 0)
+
+	for level := PackageLevel; level <= InterfaceMethodLevel; level <<= 1 {
+		if targetLevel&level == 0 {
+			continue
+		}
+
+		for name, namedLevel := range targetLevelNames {
+			if namedLevel == level {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+
+	return names
+}
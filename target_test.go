@@ -24,3 +24,17 @@ func TestFindTargetLevelFromNode(t *testing.T) {
 	assert.Equal(t, PackageLevel, FindTargetLevelFromNode(&ast.Package{}))
 	assert.Equal(t, InvalidLevel, FindTargetLevelFromNode(nil))
 }
+
+func TestTargetLevelFromNames(t *testing.T) {
+	level, err := TargetLevelFromNames([]string{"FIELD_LEVEL", "STRUCT_METHOD_LEVEL"})
+	assert.NoError(t, err)
+	assert.Equal(t, FieldLevel|StructMethodLevel, level)
+
+	_, err = TargetLevelFromNames([]string{"FOO_LEVEL"})
+	assert.EqualError(t, err, "target 'FOO_LEVEL' is not valid")
+}
+
+func TestTargetLevel_Names(t *testing.T) {
+	assert.Equal(t, []string{"STRUCT_TYPE_LEVEL", "INTERFACE_TYPE_LEVEL", "FUNCTION_LEVEL"}, (TypeLevel | FunctionLevel).Names())
+	assert.Equal(t, []string{}, InvalidLevel.Names())
+}
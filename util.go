@@ -60,3 +60,71 @@ func UpperCamelCase(str string) string {
 	}, str)
 
 }
+
+// LevenshteinDistance returns the minimum number of single-character edits
+// required to change one string into the other.
+func LevenshteinDistance(first, second string) int {
+	firstRunes := []rune(first)
+	secondRunes := []rune(second)
+
+	previousRow := make([]int, len(secondRunes)+1)
+	currentRow := make([]int, len(secondRunes)+1)
+
+	for index := range previousRow {
+		previousRow[index] = index
+	}
+
+	for firstIndex := 1; firstIndex <= len(firstRunes); firstIndex++ {
+		currentRow[0] = firstIndex
+
+		for secondIndex := 1; secondIndex <= len(secondRunes); secondIndex++ {
+			cost := 1
+
+			if firstRunes[firstIndex-1] == secondRunes[secondIndex-1] {
+				cost = 0
+			}
+
+			currentRow[secondIndex] = minInt(minInt(previousRow[secondIndex]+1, currentRow[secondIndex-1]+1), previousRow[secondIndex-1]+cost)
+		}
+
+		previousRow, currentRow = currentRow, previousRow
+	}
+
+	return previousRow[len(secondRunes)]
+}
+
+// ClosestMatch returns the candidate which is the most similar to the given value.
+// It returns false if none of the candidates is similar enough.
+func ClosestMatch(value string, candidates []string) (string, bool) {
+	closest := ""
+	closestDistance := -1
+
+	for _, candidate := range candidates {
+		distance := LevenshteinDistance(strings.ToLower(value), strings.ToLower(candidate))
+
+		if closestDistance == -1 || distance < closestDistance {
+			closest = candidate
+			closestDistance = distance
+		}
+	}
+
+	maxDistance := len(value) / 3
+
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	if closestDistance == -1 || closestDistance > maxDistance {
+		return "", false
+	}
+
+	return closest, true
+}
+
+func minInt(first, second int) int {
+	if first < second {
+		return first
+	}
+
+	return second
+}
@@ -22,3 +22,26 @@ func TestLowerCamelCase(t *testing.T) {
 func TestUpperCamelCase(t *testing.T) {
 	assert.Equal(t, "TestAny", UpperCamelCase("testAny"))
 }
+
+func TestLevenshteinDistance(t *testing.T) {
+	assert.Equal(t, 0, LevenshteinDistance("any", "any"))
+	assert.Equal(t, 1, LevenshteinDistance("any", "anu"))
+	assert.Equal(t, 2, LevenshteinDistance("Name", "Nmae"))
+	assert.Equal(t, 3, LevenshteinDistance("", "any"))
+}
+
+func TestClosestMatch(t *testing.T) {
+	candidate, ok := ClosestMatch("FEILD_LEVEL", []string{"PACKAGE_LEVEL", "FIELD_LEVEL", "FUNCTION_LEVEL"})
+	assert.True(t, ok)
+	assert.Equal(t, "FIELD_LEVEL", candidate)
+
+	candidate, ok = ClosestMatch("field_level", []string{"PACKAGE_LEVEL", "FIELD_LEVEL", "FUNCTION_LEVEL"})
+	assert.True(t, ok)
+	assert.Equal(t, "FIELD_LEVEL", candidate)
+
+	_, ok = ClosestMatch("FOO", []string{"PACKAGE_LEVEL", "FIELD_LEVEL", "FUNCTION_LEVEL"})
+	assert.False(t, ok)
+
+	_, ok = ClosestMatch("FOO", nil)
+	assert.False(t, ok)
+}
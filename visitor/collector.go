@@ -1,20 +1,36 @@
 package visitor
 
-import "github.com/procyon-projects/marker/packages"
-
+import (
+	"github.com/procyon-projects/marker/packages"
+	"sync"
+)
+
+// packageCollector keeps the files and the types of the visited packages. It is shared by the
+// goroutines visiting the packages, so its state is guarded by the mutex. The elements of a
+// package are only accessed by the goroutine visiting it until the package is processed.
 type packageCollector struct {
+	mu sync.Mutex
+	// processed is signalled whenever a package is processed.
+	processed *sync.Cond
+
 	hasSeen      map[string]bool
 	hasProcessed map[string]bool
 	files        map[string]*Files
-	packages     map[string]*packages.Package
+	// packageIds contains the ids of the packages in the order their files are added.
+	packageIds []string
+	packages   map[string]*packages.Package
 
 	unprocessedTypes map[string]map[string]Type
 
 	importTypes map[string]*ImportedType
+
+	// visitTraversed visits the package with the given id if it is one of the traversed packages,
+	// so that the packages referenced by the others are visited with their markers.
+	visitTraversed func(pkgId string) bool
 }
 
 func newPackageCollector() *packageCollector {
-	return &packageCollector{
+	collector := &packageCollector{
 		hasSeen:          make(map[string]bool),
 		hasProcessed:     make(map[string]bool),
 		files:            make(map[string]*Files),
@@ -22,45 +38,91 @@ func newPackageCollector() *packageCollector {
 		unprocessedTypes: make(map[string]map[string]Type),
 		importTypes:      make(map[string]*ImportedType),
 	}
+	collector.processed = sync.NewCond(&collector.mu)
+	return collector
 }
 
 func (collector *packageCollector) getPackage(pkgId string) *packages.Package {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
 	return collector.packages[pkgId]
 }
 
-func (collector *packageCollector) markAsSeen(pkgId string) {
+func (collector *packageCollector) addPackage(pkg *packages.Package) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	if _, ok := collector.packages[pkg.ID]; !ok {
+		collector.packages[pkg.ID] = pkg
+	}
+}
+
+// markAsSeen marks the package as seen and reports whether it was not seen before, in which case
+// the caller is responsible for visiting the package and marking it as processed.
+func (collector *packageCollector) markAsSeen(pkgId string) bool {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	if collector.hasSeen[pkgId] {
+		return false
+	}
+
 	collector.hasSeen[pkgId] = true
+	return true
 }
 
 func (collector *packageCollector) markAsProcessed(pkgId string) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
 	collector.hasProcessed[pkgId] = true
+	collector.processed.Broadcast()
 }
 
-func (collector *packageCollector) isVisited(pkgId string) bool {
-	visited, ok := collector.hasSeen[pkgId]
+// waitUntilProcessed waits until the package is processed if it is being visited by another goroutine.
+func (collector *packageCollector) waitUntilProcessed(pkgId string) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
 
-	if !ok {
-		return false
+	for collector.hasSeen[pkgId] && !collector.hasProcessed[pkgId] {
+		collector.processed.Wait()
 	}
-
-	return visited
 }
 
-func (collector *packageCollector) isProcessed(pkgId string) bool {
-	processed, ok := collector.hasProcessed[pkgId]
+// visit visits the package with the given id unless it is already seen. The packages which are not
+// traversed are loaded to be visited without markers. If the package is being visited by another
+// goroutine, it waits until the package is processed. Since the packages cannot import each other,
+// the goroutines cannot wait for each other.
+func (collector *packageCollector) visit(pkgId string) {
+	if !collector.markAsSeen(pkgId) {
+		collector.waitUntilProcessed(pkgId)
+		return
+	}
 
-	if !ok {
-		return false
+	defer collector.markAsProcessed(pkgId)
+
+	if collector.visitTraversed != nil && collector.visitTraversed(pkgId) {
+		return
 	}
 
-	return processed
+	loadResult, err := packages.LoadPackages(pkgId)
+
+	if err != nil {
+		panic(err)
+	}
+
+	pkg, _ := loadResult.Lookup(pkgId)
+	visitPackage(pkg, collector, nil)
 }
 
 func (collector *packageCollector) addFile(pkgId string, file *File) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
 	if _, ok := collector.files[pkgId]; !ok {
 		collector.files[pkgId] = &Files{
 			elements: make([]*File, 0),
 		}
+		collector.packageIds = append(collector.packageIds, pkgId)
 	}
 
 	if _, ok := collector.files[pkgId].FindByName(file.name); ok {
@@ -70,8 +132,33 @@ func (collector *packageCollector) addFile(pkgId string, file *File) {
 	collector.files[pkgId].elements = append(collector.files[pkgId].elements, file)
 }
 
+func (collector *packageCollector) addUnprocessedType(pkgId, typeName string, typ Type) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	if _, ok := collector.unprocessedTypes[pkgId]; !ok {
+		collector.unprocessedTypes[pkgId] = make(map[string]Type)
+	}
+
+	collector.unprocessedTypes[pkgId][typeName] = typ
+}
+
+// sortedFiles returns the files of all the visited packages sorted by their paths.
+func (collector *packageCollector) sortedFiles() []*File {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	var files []*File
+
+	for _, pkgId := range collector.packageIds {
+		files = append(files, collector.files[pkgId].elements...)
+	}
+
+	return sortFilesByPath(files)
+}
+
 func (collector *packageCollector) findTypeByImportAndTypeName(importName, typeName string, file *File) *ImportedType {
-	if importedType, ok := collector.importTypes[importName+"#"+typeName]; ok {
+	if importedType, ok := collector.findImportedType(importName + "#" + typeName); ok {
 		return importedType
 	}
 
@@ -81,18 +168,19 @@ func (collector *packageCollector) findTypeByImportAndTypeName(importName, typeN
 		packageImport, _ = file.imports.FindByPath(importName)
 	}
 
-	if importedType, ok := collector.importTypes[packageImport.path+"#"+typeName]; ok {
+	if importedType, ok := collector.findImportedType(packageImport.path + "#" + typeName); ok {
 		return importedType
 	}
 
-	typ, exists := collector.findTypeByPkgIdAndName(packageImport.path, typeName)
+	collector.visit(packageImport.path)
+	typ, _ := collector.findTypeByPkgIdAndName(packageImport.path, typeName)
 
-	if exists {
-		importedType := &ImportedType{
-			collector.packages[packageImport.path],
-			typ,
-		}
-		collector.importTypes[packageImport.path+"#"+typeName] = importedType
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	// the type might be found by another goroutine in the meantime
+	if importedType, ok := collector.importTypes[packageImport.path+"#"+typeName]; ok {
+		return importedType
 	}
 
 	importedType := &ImportedType{
@@ -103,7 +191,28 @@ func (collector *packageCollector) findTypeByImportAndTypeName(importName, typeN
 	return importedType
 }
 
+func (collector *packageCollector) findImportedType(key string) (*ImportedType, bool) {
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	importedType, ok := collector.importTypes[key]
+	return importedType, ok
+}
+
+// findTypeByPkgIdAndName returns the type in the files or in the unprocessed types of the package.
+// The package is visited first unless it is seen.
 func (collector *packageCollector) findTypeByPkgIdAndName(pkgId, typeName string) (Type, bool) {
+	collector.mu.Lock()
+	_, ok := collector.files[pkgId]
+	seen := collector.hasSeen[pkgId]
+	collector.mu.Unlock()
+
+	if !ok && !seen {
+		collector.visit(pkgId)
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
 	if files, ok := collector.files[pkgId]; ok {
 
 		for i := 0; i < files.Len(); i++ {
@@ -126,22 +235,6 @@ func (collector *packageCollector) findTypeByPkgIdAndName(pkgId, typeName string
 			}
 		}
 
-	} else if !collector.isVisited(pkgId) {
-		loadResult, err := packages.LoadPackages(pkgId)
-
-		if err != nil {
-			panic(err)
-		}
-
-		pkg, _ := loadResult.Lookup(pkgId)
-
-		visitPackage(pkg, collector, nil) //collector.allPackageMarkers)
-
-		typ, ok := collector.findTypeByPkgIdAndName(pkgId, typeName)
-
-		if ok {
-			return typ, true
-		}
 	}
 
 	if typ, ok := collector.unprocessedTypes[pkgId][typeName]; ok {
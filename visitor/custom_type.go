@@ -53,6 +53,28 @@ func (c *CustomType) AliasType() Type {
 	return c.aliasType
 }
 
+func (c *CustomType) File() *File {
+	return c.file
+}
+
+func (c *CustomType) Position() Position {
+	return c.position
+}
+
+func (c *CustomType) Markers() markers.MarkerValues {
+	return c.markers
+}
+
+func (c *CustomType) NumMethods() int {
+	return len(c.methods)
+}
+
+func (c *CustomType) Methods() *Functions {
+	return &Functions{
+		elements: c.methods,
+	}
+}
+
 func (c *CustomType) Underlying() Type {
 	return c
 }
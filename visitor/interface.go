@@ -97,7 +97,7 @@ func (i *Interface) initialize(specType *ast.TypeSpec, interfaceType *ast.Interf
 func (i *Interface) getInterfaceMethods() []*Function {
 	methods := make([]*Function, 0)
 
-	markers := i.visitor.allPackageMarkers[i.pkg.ID]
+	markers := i.visitor.packageMarkers
 
 	for _, rawMethod := range i.fieldList {
 		_, ok := rawMethod.Type.(*ast.FuncType)
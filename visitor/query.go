@@ -0,0 +1,496 @@
+package visitor
+
+import (
+	"fmt"
+	"github.com/procyon-projects/marker"
+	"github.com/procyon-projects/marker/packages"
+	"go/ast"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	structKind     = "struct"
+	interfaceKind  = "interface"
+	customTypeKind = "type"
+	functionKind   = "function"
+	methodKind     = "method"
+	fieldKind      = "field"
+	anyKind        = "*"
+)
+
+type queryCombinator int
+
+const (
+	noCombinator queryCombinator = iota
+	childCombinator
+	descendantCombinator
+)
+
+type queryFilterKind int
+
+const (
+	markerFilter queryFilterKind = iota
+	nameFilter
+	exportedFilter
+	unexportedFilter
+	embeddedFilter
+)
+
+type queryFilter struct {
+	kind     queryFilterKind
+	negated  bool
+	name     string
+	value    string
+	hasValue bool
+}
+
+type elementSelector struct {
+	kind    string
+	filters []queryFilter
+	// combinator is how the element is related to the element matched by the previous selector.
+	combinator queryCombinator
+}
+
+// Query selects the elements by their kinds, names and markers. A query consists of the selectors
+// separated by commas, and a selector consists of the element selectors separated by '>' for
+// the children or by whitespace for the descendants of the previous element:
+//
+//	struct[+orm:table][exported] > field[+orm:column:Nullable=true]
+//
+// An element selector starts with one of the kinds struct, interface, type, function, method,
+// field or '*', which can be omitted, and it is followed by the filters in brackets:
+//
+//	[+name]             the element has the marker
+//	[+name=value]       the marker or its Value argument equals to the value
+//	[+name:Arg=value]   the argument of the marker equals to the value
+//	[name=value]        the name of the element equals to the value
+//	[exported]          the element is exported, [unexported] is the opposite
+//	[embedded]          the field is embedded
+//
+// A filter is negated by '!' such as [!+orm:ignore], and the values can be quoted. The values of
+// the markers are compared in their text forms, a slice matches if any of its items matches.
+type Query struct {
+	text      string
+	selectors [][]elementSelector
+}
+
+// Match is an element matched by a query with its kind.
+type Match struct {
+	Kind    string
+	Element Element
+}
+
+// ParseQuery parses the given text as a query.
+func ParseQuery(text string) (*Query, error) {
+	parser := &queryParser{
+		text: text,
+	}
+
+	selectors, err := parser.parse()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Query{
+		text:      text,
+		selectors: selectors,
+	}, nil
+}
+
+func (q *Query) String() string {
+	return q.text
+}
+
+// Select returns the elements in the given packages matching the query in the order of FindByMarker.
+// The errors of the markers which cannot be collected are returned along with the matches.
+func (q *Query) Select(collector *markers.Collector, pkgs []*packages.Package) ([]Match, error) {
+	var matches []Match
+
+	err := eachPackageFile(collector, pkgs, func(file *File) error {
+		for _, node := range fileElements(file) {
+			if q.matches(node) {
+				matches = append(matches, Match{
+					Kind:    node.kind,
+					Element: node.element,
+				})
+			}
+		}
+
+		return nil
+	})
+
+	return matches, err
+}
+
+func (q *Query) matches(node *elementNode) bool {
+	for _, selectors := range q.selectors {
+		if matchSelectors(selectors, node) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchSelectors(selectors []elementSelector, node *elementNode) bool {
+	last := selectors[len(selectors)-1]
+
+	if !last.matches(node) {
+		return false
+	}
+
+	if len(selectors) == 1 {
+		return true
+	}
+
+	previous := selectors[:len(selectors)-1]
+
+	if last.combinator == childCombinator {
+		return node.parent != nil && matchSelectors(previous, node.parent)
+	}
+
+	for ancestor := node.parent; ancestor != nil; ancestor = ancestor.parent {
+		if matchSelectors(previous, ancestor) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s elementSelector) matches(node *elementNode) bool {
+	if s.kind != anyKind && s.kind != node.kind {
+		return false
+	}
+
+	for _, filter := range s.filters {
+		if filter.matches(node.element) == filter.negated {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f queryFilter) matches(element Element) bool {
+	switch f.kind {
+	case nameFilter:
+		return element.Name() == f.value
+	case exportedFilter:
+		return ast.IsExported(element.Name())
+	case unexportedFilter:
+		return !ast.IsExported(element.Name())
+	case embeddedFilter:
+		field, ok := element.(*Field)
+		return ok && field.IsEmbedded()
+	}
+
+	return f.matchesMarkers(element.Markers())
+}
+
+// matchesMarkers checks if the marker values contain the marker of the filter. If there is no
+// marker with the name of the filter, its last part after ':' is taken as the argument name.
+func (f queryFilter) matchesMarkers(markerValues markers.MarkerValues) bool {
+	if values, ok := markerValues[f.name]; ok {
+		if !f.hasValue {
+			return true
+		}
+
+		return matchesMarkerArgument(values, markers.ValueArgument, f.value)
+	}
+
+	index := strings.LastIndex(f.name, ":")
+
+	if !f.hasValue || index == -1 {
+		return false
+	}
+
+	return matchesMarkerArgument(markerValues[f.name[:index]], f.name[index+1:], f.value)
+}
+
+func matchesMarkerArgument(values []any, name, text string) bool {
+	for _, value := range values {
+		if argument, ok := markerArgument(value, name); ok && matchesQueryValue(argument, text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// markerArgument returns the value of the argument with the given name in the marker value. The
+// markers which are not structs have only the Value argument.
+func markerArgument(value any, name string) (any, bool) {
+	if dynamicValue, ok := value.(*markers.DynamicValue); ok {
+		return dynamicValue.Get(name)
+	}
+
+	reflectValue := reflect.ValueOf(value)
+
+	for reflectValue.Kind() == reflect.Ptr {
+		if reflectValue.IsNil() {
+			return nil, false
+		}
+
+		reflectValue = reflectValue.Elem()
+	}
+
+	if reflectValue.Kind() != reflect.Struct {
+		return value, name == markers.ValueArgument
+	}
+
+	reflectType := reflectValue.Type()
+
+	for index := 0; index < reflectType.NumField(); index++ {
+		field := reflectType.Field(index)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		argumentName := markers.UpperCamelCase(field.Name)
+
+		if parameterTag, ok := field.Tag.Lookup("parameter"); ok && parameterTag != "" {
+			argumentName = parameterTag
+		}
+
+		if argumentName == name {
+			return reflectValue.Field(index).Interface(), true
+		}
+	}
+
+	return nil, false
+}
+
+func matchesQueryValue(value any, text string) bool {
+	reflectValue := reflect.ValueOf(value)
+
+	switch reflectValue.Kind() {
+	case reflect.Invalid:
+		return false
+	case reflect.Ptr, reflect.Interface:
+		return !reflectValue.IsNil() && matchesQueryValue(reflectValue.Elem().Interface(), text)
+	case reflect.Slice, reflect.Array:
+		for index := 0; index < reflectValue.Len(); index++ {
+			if matchesQueryValue(reflectValue.Index(index).Interface(), text) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return fmt.Sprint(value) == text
+}
+
+type queryParser struct {
+	text   string
+	offset int
+}
+
+func (p *queryParser) parse() ([][]elementSelector, error) {
+	var selectors [][]elementSelector
+
+	for {
+		compound, err := p.parseSelectors()
+
+		if err != nil {
+			return nil, err
+		}
+
+		selectors = append(selectors, compound)
+
+		if p.offset == len(p.text) {
+			return selectors, nil
+		}
+
+		// skip ','
+		p.offset++
+	}
+}
+
+func (p *queryParser) parseSelectors() ([]elementSelector, error) {
+	var selectors []elementSelector
+
+	for {
+		hasSpace := p.skipSpaces()
+
+		if p.offset == len(p.text) || p.text[p.offset] == ',' {
+			if len(selectors) == 0 {
+				return nil, p.errorf("selector is expected")
+			}
+
+			return selectors, nil
+		}
+
+		combinator := noCombinator
+
+		if len(selectors) != 0 {
+			combinator = descendantCombinator
+
+			if p.text[p.offset] == '>' {
+				combinator = childCombinator
+				p.offset++
+				p.skipSpaces()
+			} else if !hasSpace {
+				return nil, p.errorf("unexpected character '%c'", p.text[p.offset])
+			}
+		}
+
+		selector, err := p.parseSelector()
+
+		if err != nil {
+			return nil, err
+		}
+
+		selector.combinator = combinator
+		selectors = append(selectors, selector)
+	}
+}
+
+func (p *queryParser) parseSelector() (elementSelector, error) {
+	start := p.offset
+
+	for p.offset < len(p.text) && (isQueryLetter(p.text[p.offset]) || p.text[p.offset] == '*') {
+		p.offset++
+	}
+
+	selector := elementSelector{
+		kind: p.text[start:p.offset],
+	}
+
+	switch selector.kind {
+	case "":
+		if p.offset == len(p.text) || p.text[p.offset] != '[' {
+			return selector, p.errorf("element kind is expected")
+		}
+
+		selector.kind = anyKind
+	case structKind, interfaceKind, customTypeKind, functionKind, methodKind, fieldKind, anyKind:
+	default:
+		p.offset = start
+		return selector, p.errorf("unknown element kind '%s'", selector.kind)
+	}
+
+	for p.offset < len(p.text) && p.text[p.offset] == '[' {
+		filter, err := p.parseFilter()
+
+		if err != nil {
+			return selector, err
+		}
+
+		selector.filters = append(selector.filters, filter)
+	}
+
+	return selector, nil
+}
+
+func (p *queryParser) parseFilter() (queryFilter, error) {
+	start := p.offset
+	quote := byte(0)
+
+	// skip '['
+	p.offset++
+
+	for ; p.offset < len(p.text); p.offset++ {
+		character := p.text[p.offset]
+
+		switch {
+		case quote == '"' && character == '\\':
+			p.offset++
+		case quote != 0:
+			if character == quote {
+				quote = 0
+			}
+		case character == '"' || character == '`':
+			quote = character
+		case character == ']':
+			p.offset++
+			return p.parseFilterText(start, p.text[start+1:p.offset-1])
+		}
+	}
+
+	p.offset = start
+	return queryFilter{}, p.errorf("'[' is not closed")
+}
+
+func (p *queryParser) parseFilterText(offset int, text string) (queryFilter, error) {
+	filter := queryFilter{}
+	text = strings.TrimSpace(text)
+
+	if strings.HasPrefix(text, "!") {
+		filter.negated = true
+		text = strings.TrimSpace(text[1:])
+	}
+
+	name, value, hasValue := strings.Cut(text, "=")
+	name = strings.TrimSpace(name)
+
+	if hasValue {
+		value = strings.TrimSpace(value)
+
+		if strings.HasPrefix(value, "\"") || strings.HasPrefix(value, "`") {
+			unquoted, err := strconv.Unquote(value)
+
+			if err != nil {
+				p.offset = offset
+				return filter, p.errorf("invalid value %s", value)
+			}
+
+			value = unquoted
+		}
+
+		filter.value = value
+		filter.hasValue = true
+	}
+
+	switch {
+	case strings.HasPrefix(name, "+"):
+		filter.kind = markerFilter
+		filter.name = strings.TrimSpace(name[1:])
+
+		if filter.name == "" {
+			p.offset = offset
+			return filter, p.errorf("marker name is expected")
+		}
+
+		return filter, nil
+	case name == "name" && hasValue:
+		filter.kind = nameFilter
+		return filter, nil
+	case name == "exported" && !hasValue:
+		filter.kind = exportedFilter
+		return filter, nil
+	case name == "unexported" && !hasValue:
+		filter.kind = unexportedFilter
+		return filter, nil
+	case name == "embedded" && !hasValue:
+		filter.kind = embeddedFilter
+		return filter, nil
+	}
+
+	p.offset = offset
+	return filter, p.errorf("unknown filter '%s'", text)
+}
+
+// skipSpaces skips the whitespaces and reports whether any whitespace is skipped.
+func (p *queryParser) skipSpaces() bool {
+	start := p.offset
+
+	for p.offset < len(p.text) && strings.ContainsRune(" \t\r\n", rune(p.text[p.offset])) {
+		p.offset++
+	}
+
+	return p.offset != start
+}
+
+func (p *queryParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s at offset %d in query '%s'", fmt.Sprintf(format, args...), p.offset, p.text)
+}
+
+func isQueryLetter(character byte) bool {
+	return character >= 'a' && character <= 'z' || character >= 'A' && character <= 'Z'
+}
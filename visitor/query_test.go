@@ -0,0 +1,133 @@
+package visitor
+
+import (
+	"fmt"
+	"github.com/procyon-projects/marker"
+	"github.com/procyon-projects/marker/packages"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func selectQuery(t *testing.T, collector *markers.Collector, pkgs []*packages.Package, text string) []string {
+	query, err := ParseQuery(text)
+	assert.NoError(t, err)
+
+	matches, err := query.Select(collector, pkgs)
+	assert.NoError(t, err)
+
+	var selected []string
+	for _, match := range matches {
+		selected = append(selected, fmt.Sprintf("%s %s %d:%d", match.Kind, match.Element.Name(), match.Element.Position().Line, match.Element.Position().Column))
+	}
+
+	return selected
+}
+
+func TestQuery_Select(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected []string
+	}{
+		{
+			query:    "struct[+marker:struct-type-level] > field[+marker:struct-field-level]",
+			expected: []string{"field cookieDough 37:2", "field ChocolateChip 59:2", "field tripleChocolateCookie 62:2"},
+		},
+		{
+			query:    "struct[exported] method",
+			expected: []string{"method Eat 24:1", "method Buy 42:1"},
+		},
+		{
+			query:    "field[embedded], field[+marker:struct-field-level:Name=ChocolateChip]",
+			expected: []string{"field cookie 0:0", "field ChocolateChip 59:2"},
+		},
+		{
+			query:    "[+marker:struct-type-level:Any=\"map[key:value]\"]",
+			expected: []string{"struct cookie 56:6"},
+		},
+		{
+			query:    "interface[+marker:interface-type-level:Name=Dessert] > method[unexported]",
+			expected: []string{"method muffin 108:8"},
+		},
+		{
+			query:    "method[name=Macaron]",
+			expected: []string{"method Macaron 133:9"},
+		},
+		{
+			query:    "function[!+marker:function-level], type",
+			expected: []string{"type errorList 3:6", "function GenericFunction 3:1", "type Permission 6:6", "type RequestMethod 14:6", "type Chan 23:6", "type Coffee 6:6", "type Lemonade 6:6"},
+		},
+		{
+			query:    "*[+marker:struct-method-level:Name=Buy]",
+			expected: []string{"method Buy 42:1"},
+		},
+		{
+			query:    "interface method[+marker:interface-method-level:Name=IceCream]",
+			expected: []string{"method IceCream 84:10"},
+		},
+	}
+
+	collector := newTraversalTestCollector(t)
+	pkgs := loadTraversalTestPackages(t)
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, selectQuery(t, collector, pkgs, testCase.query), testCase.query)
+	}
+}
+
+type queryTestMarker struct {
+	Name     string `parameter:"name"`
+	Nullable bool
+	Tags     []string
+}
+
+func TestQueryFilter_MatchesMarkers(t *testing.T) {
+	markerValues := markers.MarkerValues{
+		"test:value":  {"anyValue"},
+		"test:column": {queryTestMarker{Name: "id", Nullable: true, Tags: []string{"key", "index"}}},
+	}
+
+	testCases := map[string]bool{
+		"[+test:value]":                  true,
+		"[+test:value=anyValue]":         true,
+		"[+test:value:Value=anyValue]":   true,
+		"[+test:value=otherValue]":       false,
+		"[+test:column]":                 true,
+		"[+test:column:name=id]":         true,
+		"[+test:column:Name=id]":         false,
+		"[+test:column:Nullable=true]":   true,
+		"[+test:column:Tags=index]":      true,
+		"[+test:column:Tags=\"key\"]":    true,
+		"[+test:column:Tags=unique]":     false,
+		"[!+test:column:Nullable=false]": true,
+		"[+test:index]":                  false,
+		"[+test:index:Name=id]":          false,
+	}
+
+	for text, expected := range testCases {
+		query, err := ParseQuery(text)
+		assert.NoError(t, err)
+		filter := query.selectors[0][0].filters[0]
+		assert.Equal(t, expected, filter.matchesMarkers(markerValues) != filter.negated, text)
+	}
+}
+
+func TestParseQuery_ShouldReturnErrorIfQueryIsInvalid(t *testing.T) {
+	testCases := map[string]string{
+		"":                      "selector is expected at offset 0 in query ''",
+		"struct,":               "selector is expected at offset 7 in query 'struct,'",
+		"class[exported]":       "unknown element kind 'class' at offset 0 in query 'class[exported]'",
+		"struct >":              "element kind is expected at offset 8 in query 'struct >'",
+		"struct[exported":       "'[' is not closed at offset 6 in query 'struct[exported'",
+		"field[+]":              "marker name is expected at offset 5 in query 'field[+]'",
+		"field[public]":         "unknown filter 'public' at offset 5 in query 'field[public]'",
+		"field[exported=true]":  "unknown filter 'exported=true' at offset 5 in query 'field[exported=true]'",
+		"field[name=\"id]":      "'[' is not closed at offset 5 in query 'field[name=\"id]'",
+		"struct[exported]field": "unexpected character 'f' at offset 16 in query 'struct[exported]field'",
+	}
+
+	for text, expected := range testCases {
+		query, err := ParseQuery(text)
+		assert.Nil(t, query, text)
+		assert.EqualError(t, err, expected, text)
+	}
+}
@@ -39,6 +39,18 @@ func (f *Field) Tags() string {
 	return f.tags
 }
 
+func (f *Field) Position() Position {
+	return f.position
+}
+
+func (f *Field) Markers() markers.MarkerValues {
+	return f.markers
+}
+
+func (f *Field) File() *File {
+	return f.file
+}
+
 type Fields struct {
 	elements []*Field
 }
@@ -135,7 +147,7 @@ func (s *Struct) initialize(specType *ast.TypeSpec, structType *ast.StructType,
 func (s *Struct) getFieldsFromFieldList() []*Field {
 	fields := make([]*Field, 0)
 
-	markers := s.visitor.allPackageMarkers[s.pkg.ID]
+	markers := s.visitor.packageMarkers
 
 	for _, rawField := range s.fieldList {
 		tags := ""
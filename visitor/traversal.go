@@ -0,0 +1,287 @@
+package visitor
+
+import (
+	"fmt"
+	"github.com/procyon-projects/marker"
+	"github.com/procyon-projects/marker/packages"
+	"sort"
+)
+
+// Package is a visited package with its files.
+type Package struct {
+	pkg   *packages.Package
+	files []*File
+}
+
+func (p *Package) ID() string {
+	return p.pkg.ID
+}
+
+func (p *Package) Name() string {
+	return p.pkg.Name
+}
+
+func (p *Package) Path() string {
+	return p.pkg.PkgPath
+}
+
+func (p *Package) Package() *packages.Package {
+	return p.pkg
+}
+
+func (p *Package) Files() *Files {
+	return &Files{
+		elements: p.files,
+	}
+}
+
+// Element is implemented by the visited elements which can have markers.
+type Element interface {
+	Name() string
+	File() *File
+	Position() Position
+	Markers() markers.MarkerValues
+}
+
+type PackageCallback func(pkg *Package, err error) error
+
+// StructCallback is invoked for each struct. Like the other element callbacks, it stops the traversal
+// by returning an error, which is returned by the traversal function unless it is SkipAll. Otherwise,
+// the errors of the markers which cannot be collected are returned once all the elements are visited,
+// and the elements having such markers are visited without them.
+type StructCallback func(structType *Struct) error
+
+type InterfaceCallback func(interfaceType *Interface) error
+
+type FunctionCallback func(function *Function) error
+
+type FieldCallback func(structType *Struct, field *Field) error
+
+// EachPackage invokes the callback for the given packages sorted by their ids with the errors of
+// the markers in their files. The errors returned by the callback are collected with the ids of
+// the packages as in EachFile, but the packages which are visited only because their types are
+// referenced are not included.
+func EachPackage(collector *markers.Collector, pkgs []*packages.Package, callback PackageCallback) error {
+	pkgCollector, fileErrors, err := visitPackages(collector, pkgs, Options{})
+
+	if err != nil {
+		return err
+	}
+
+	var visitedPackages []*Package
+
+	for _, pkg := range sortedPackages(pkgs) {
+		if files, ok := pkgCollector.files[pkg.ID]; ok {
+			visitedPackages = append(visitedPackages, &Package{
+				pkg:   pkg,
+				files: sortFilesByPath(files.elements),
+			})
+		}
+	}
+
+	var errs []error
+
+	for _, pkg := range visitedPackages {
+		var pkgErrs []error
+
+		for _, file := range pkg.files {
+			pkgErrs = append(pkgErrs, fileErrors[file.Path()]...)
+		}
+
+		err = callback(pkg, markers.NewErrorList(pkgErrs))
+
+		if err == SkipAll {
+			break
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", pkg.ID(), err))
+		}
+	}
+
+	return markers.NewErrorList(errs)
+}
+
+// EachStruct invokes the callback for the structs declared in the given packages.
+func EachStruct(collector *markers.Collector, pkgs []*packages.Package, callback StructCallback) error {
+	return eachPackageFile(collector, pkgs, func(file *File) error {
+		for _, structType := range file.Structs().elements {
+			if err := callback(structType); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// EachInterface invokes the callback for the interfaces declared in the given packages.
+func EachInterface(collector *markers.Collector, pkgs []*packages.Package, callback InterfaceCallback) error {
+	return eachPackageFile(collector, pkgs, func(file *File) error {
+		for _, interfaceType := range file.Interfaces().elements {
+			if err := callback(interfaceType); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// EachFunction invokes the callback for the functions declared in the given packages,
+// the methods are reached from their receiver types.
+func EachFunction(collector *markers.Collector, pkgs []*packages.Package, callback FunctionCallback) error {
+	return eachPackageFile(collector, pkgs, func(file *File) error {
+		for _, function := range file.Functions().elements {
+			if err := callback(function); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// EachField invokes the callback for the fields of the structs declared in the given packages.
+func EachField(collector *markers.Collector, pkgs []*packages.Package, callback FieldCallback) error {
+	return eachPackageFile(collector, pkgs, func(file *File) error {
+		for _, structType := range file.Structs().elements {
+			for _, field := range structType.Fields().elements {
+				if err := callback(structType, field); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// FindByMarker returns the elements having the marker with the given name in the given packages.
+// The elements are returned in the order of their files and the types, followed by their fields
+// and methods, and then the functions. The errors of the markers which cannot be collected are
+// returned along with the elements found.
+func FindByMarker(collector *markers.Collector, pkgs []*packages.Package, name string) ([]Element, error) {
+	var elements []Element
+
+	err := eachPackageFile(collector, pkgs, func(file *File) error {
+		for _, node := range fileElements(file) {
+			if node.element.Markers().CountByName(name) != 0 {
+				elements = append(elements, node.element)
+			}
+		}
+
+		return nil
+	})
+
+	return elements, err
+}
+
+// elementNode is an element with its kind and the element declaring it.
+type elementNode struct {
+	kind    string
+	element Element
+	parent  *elementNode
+}
+
+// fileElements returns the elements declared in the file which can have markers.
+func fileElements(file *File) []*elementNode {
+	var nodes []*elementNode
+
+	for _, structType := range file.Structs().elements {
+		structNode := &elementNode{kind: structKind, element: structType}
+		nodes = append(nodes, structNode)
+
+		for _, field := range structType.Fields().elements {
+			nodes = append(nodes, &elementNode{kind: fieldKind, element: field, parent: structNode})
+		}
+
+		for _, method := range structType.Methods().elements {
+			nodes = append(nodes, &elementNode{kind: methodKind, element: method, parent: structNode})
+		}
+	}
+
+	for _, interfaceType := range file.Interfaces().elements {
+		interfaceNode := &elementNode{kind: interfaceKind, element: interfaceType}
+		nodes = append(nodes, interfaceNode)
+
+		for _, method := range interfaceType.ExplicitMethods().elements {
+			nodes = append(nodes, &elementNode{kind: methodKind, element: method, parent: interfaceNode})
+		}
+	}
+
+	for _, customType := range file.CustomTypes().elements {
+		customTypeNode := &elementNode{kind: customTypeKind, element: customType}
+		nodes = append(nodes, customTypeNode)
+
+		for _, method := range customType.methods {
+			nodes = append(nodes, &elementNode{kind: methodKind, element: method, parent: customTypeNode})
+		}
+	}
+
+	for _, function := range file.Functions().elements {
+		nodes = append(nodes, &elementNode{kind: functionKind, element: function})
+	}
+
+	return nodes
+}
+
+// eachPackageFile invokes the callback for the files of the given packages sorted by their paths,
+// and returns the errors of the markers which cannot be collected. The traversal is stopped if
+// the callback returns an error, the error is returned unless it is SkipAll.
+func eachPackageFile(collector *markers.Collector, pkgs []*packages.Package, callback func(file *File) error) error {
+	pkgCollector, fileErrors, err := visitPackages(collector, pkgs, Options{})
+
+	if err != nil {
+		return err
+	}
+
+	pkgIds := make(map[string]bool, len(pkgs))
+
+	for _, pkg := range pkgs {
+		pkgIds[pkg.ID] = true
+	}
+
+	var errs []error
+
+	for _, file := range pkgCollector.sortedFiles() {
+		if !pkgIds[file.pkg.ID] {
+			continue
+		}
+
+		errs = append(errs, fileErrors[file.Path()]...)
+		err = callback(file)
+
+		if err == SkipAll {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return markers.NewErrorList(errs)
+}
+
+func sortedPackages(pkgs []*packages.Package) []*packages.Package {
+	sorted := make([]*packages.Package, len(pkgs))
+	copy(sorted, pkgs)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	return sorted
+}
+
+func sortFilesByPath(files []*File) []*File {
+	sorted := make([]*File, len(files))
+	copy(sorted, files)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Path() < sorted[j].Path()
+	})
+
+	return sorted
+}
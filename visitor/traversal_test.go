@@ -0,0 +1,150 @@
+package visitor
+
+import (
+	"errors"
+	"fmt"
+	"github.com/procyon-projects/marker"
+	"github.com/procyon-projects/marker/packages"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newTraversalTestCollector(t *testing.T) *markers.Collector {
+	registry := markers.NewRegistry()
+	assert.NoError(t, registry.Register("marker:package-level", "github.com/procyon-projects/marker", markers.PackageLevel, &PackageLevel{}))
+	assert.NoError(t, registry.Register("marker:interface-type-level", "github.com/procyon-projects/marker", markers.InterfaceTypeLevel, &InterfaceTypeLevel{}))
+	assert.NoError(t, registry.Register("marker:interface-method-level", "github.com/procyon-projects/marker", markers.InterfaceMethodLevel, &InterfaceMethodLevel{}))
+	assert.NoError(t, registry.Register("marker:function-level", "github.com/procyon-projects/marker", markers.FunctionLevel, &FunctionLevel{}))
+	assert.NoError(t, registry.Register("marker:struct-type-level", "github.com/procyon-projects/marker", markers.StructTypeLevel, &StructTypeLevel{}))
+	assert.NoError(t, registry.Register("marker:struct-method-level", "github.com/procyon-projects/marker", markers.StructMethodLevel, &StructMethodLevel{}))
+	assert.NoError(t, registry.Register("marker:struct-field-level", "github.com/procyon-projects/marker", markers.FieldLevel, &StructFieldLevel{}))
+	return markers.NewCollector(registry)
+}
+
+func loadTraversalTestPackages(t *testing.T) []*packages.Package {
+	result, err := packages.LoadPackages("../test/...")
+	assert.NoError(t, err)
+
+	var pkgs []*packages.Package
+	for _, pkgPath := range []string{"github.com/procyon-projects/marker/test/menu", "github.com/procyon-projects/marker/test/any"} {
+		pkg, _ := result.Lookup(pkgPath)
+		pkgs = append(pkgs, pkg)
+	}
+
+	return pkgs
+}
+
+func TestEachPackage(t *testing.T) {
+	var visited []string
+	err := EachPackage(newTraversalTestCollector(t), loadTraversalTestPackages(t), func(pkg *Package, err error) error {
+		assert.NoError(t, err)
+
+		var fileNames []string
+		for index := 0; index < pkg.Files().Len(); index++ {
+			fileNames = append(fileNames, pkg.Files().At(index).Name())
+		}
+
+		visited = append(visited, fmt.Sprintf("%s %s %v", pkg.Path(), pkg.Name(), fileNames))
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"github.com/procyon-projects/marker/test/any any [error.go generics.go math.go permission.go string.go]",
+		"github.com/procyon-projects/marker/test/menu menu [coffee.go dessert.go fresh.go]",
+	}, visited)
+
+	visited = nil
+	err = EachPackage(newTraversalTestCollector(t), loadTraversalTestPackages(t), func(pkg *Package, err error) error {
+		visited = append(visited, pkg.ID())
+		return SkipAll
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"github.com/procyon-projects/marker/test/any"}, visited)
+
+	err = EachPackage(newTraversalTestCollector(t), loadTraversalTestPackages(t), func(pkg *Package, err error) error {
+		return errors.New("anyError")
+	})
+
+	assert.EqualError(t, err, "[github.com/procyon-projects/marker/test/any: anyError github.com/procyon-projects/marker/test/menu: anyError]")
+}
+
+func TestEachStruct(t *testing.T) {
+	var structs []string
+	err := EachStruct(newTraversalTestCollector(t), loadTraversalTestPackages(t), func(structType *Struct) error {
+		structs = append(structs, fmt.Sprintf("%s %v", structType.Name(), structType.Markers().First("marker:struct-type-level")))
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"FriedCookie {FriedCookie <nil>}", "cookie {cookie map[key:value]}"}, structs)
+}
+
+func TestEachInterface(t *testing.T) {
+	var interfaces []string
+	err := EachInterface(newTraversalTestCollector(t), loadTraversalTestPackages(t), func(interfaceType *Interface) error {
+		interfaces = append(interfaces, interfaceType.Name())
+
+		if interfaceType.Name() == "Dessert" {
+			return SkipAll
+		}
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BakeryShop", "newYearsEveCookie", "Dessert"}, interfaces)
+}
+
+func TestEachFunction(t *testing.T) {
+	var functions []string
+	err := EachFunction(newTraversalTestCollector(t), loadTraversalTestPackages(t), func(function *Function) error {
+		functions = append(functions, function.Name())
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GenericFunction", "MakeACake", "BiscuitCake"}, functions)
+
+	err = EachFunction(newTraversalTestCollector(t), loadTraversalTestPackages(t), func(function *Function) error {
+		return errors.New("anyError")
+	})
+
+	assert.EqualError(t, err, "anyError")
+}
+
+func TestEachField(t *testing.T) {
+	var fields []string
+	err := EachField(newTraversalTestCollector(t), loadTraversalTestPackages(t), func(structType *Struct, field *Field) error {
+		fields = append(fields, fmt.Sprintf("%s.%s %d:%d %d", structType.Name(), field.Name(), field.Position().Line, field.Position().Column, field.Markers().Count()))
+		assert.Equal(t, structType.File(), field.File())
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"FriedCookie.cookie 0:0 0",
+		"FriedCookie.cookieDough 37:2 1",
+		"cookie.ChocolateChip 59:2 1",
+		"cookie.tripleChocolateCookie 62:2 1",
+	}, fields)
+}
+
+func TestFindByMarker(t *testing.T) {
+	elements, err := FindByMarker(newTraversalTestCollector(t), loadTraversalTestPackages(t), "marker:struct-method-level")
+	assert.NoError(t, err)
+
+	var names []string
+	for _, element := range elements {
+		names = append(names, fmt.Sprintf("%s %s %d", element.File().Name(), element.Name(), element.Position().Line))
+	}
+
+	assert.Equal(t, []string{"dessert.go Eat 24", "dessert.go Buy 42", "dessert.go FortuneCookie 67", "dessert.go Oreo 73"}, names)
+
+	elements, err = FindByMarker(newTraversalTestCollector(t), loadTraversalTestPackages(t), "marker:function-level")
+	assert.NoError(t, err)
+	assert.Len(t, elements, 2)
+	assert.Equal(t, FunctionLevel{Name: "MakeACake"}, elements[0].Markers().First("marker:function-level"))
+	assert.IsType(t, &Function{}, elements[0])
+}
@@ -178,10 +178,6 @@ func getTypeFromScope(name string, visitor *packageVisitor) Type {
 
 	typedName, ok := typ.Type().(*types.Named)
 
-	if _, ok := visitor.collector.unprocessedTypes[pkg.ID]; !ok {
-		visitor.collector.unprocessedTypes[pkg.ID] = make(map[string]Type)
-	}
-
 	if ok {
 		switch typedName.Underlying().(type) {
 		case *types.Struct:
@@ -189,21 +185,21 @@ func getTypeFromScope(name string, visitor *packageVisitor) Type {
 				name:        name,
 				isProcessed: false,
 			}
-			visitor.collector.unprocessedTypes[pkg.ID][name] = structType
+			visitor.collector.addUnprocessedType(pkg.ID, name, structType)
 			return structType
 		case *types.Interface:
 			interfaceType := &Interface{
 				name:        name,
 				isProcessed: false,
 			}
-			visitor.collector.unprocessedTypes[pkg.ID][name] = interfaceType
+			visitor.collector.addUnprocessedType(pkg.ID, name, interfaceType)
 			return interfaceType
 		default:
 			customType := &CustomType{
 				name:        name,
 				isProcessed: false,
 			}
-			visitor.collector.unprocessedTypes[pkg.ID][name] = customType
+			visitor.collector.addUnprocessedType(pkg.ID, name, customType)
 			return customType
 		}
 	}
@@ -251,6 +247,17 @@ func collectTypeFromTypeSpec(typeSpec *ast.TypeSpec, visitor *packageVisitor) Ty
 	}
 }
 
+// findBuiltinType returns the type declared in the builtin package. The builtin package is visited
+// first unless the type is referenced by the builtin package itself.
+func findBuiltinType(typeName string, visitor *packageVisitor) Type {
+	if visitor.pkg.ID != "builtin" {
+		visitor.collector.visit("builtin")
+	}
+
+	typ, _ := visitor.collector.findTypeByPkgIdAndName("builtin", typeName)
+	return typ
+}
+
 func getTypeFromExpression(expr ast.Expr, file *File, visitor *packageVisitor) Type {
 	pkg := visitor.pkg
 	collector := visitor.collector
@@ -266,11 +273,9 @@ func getTypeFromExpression(expr ast.Expr, file *File, visitor *packageVisitor) T
 		}
 
 		if typed.Name == "error" {
-			errorType, _ := collector.findTypeByPkgIdAndName("builtin", "error")
-			return errorType
+			return findBuiltinType("error", visitor)
 		} else if typed.Name == "any" {
-			anyType, _ := collector.findTypeByPkgIdAndName("builtin", "any")
-			return anyType
+			return findBuiltinType("any", visitor)
 		}
 
 		typ, ok = collector.findTypeByPkgIdAndName(pkg.ID, typed.Name)
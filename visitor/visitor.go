@@ -2,20 +2,28 @@ package visitor
 
 import (
 	"errors"
+	"fmt"
 	"github.com/procyon-projects/marker"
 	"github.com/procyon-projects/marker/packages"
 	"go/ast"
 	"go/token"
+	"runtime"
+	"sync"
 )
 
+// FileCallback is invoked for each file with the errors of the markers in the file. The errors returned
+// by the callback are collected with the paths of the files, unless SkipAll is returned to stop the traversal.
 type FileCallback func(file *File, err error) error
 
+// SkipAll is used as a return value from FileCallback to indicate that the remaining files are to be skipped.
+// It is not returned as an error by any function.
+var SkipAll = errors.New("skip all the remaining files")
+
 type packageVisitor struct {
 	collector *packageCollector
 
-	pkg               *packages.Package
-	packageMarkers    map[ast.Node]markers.MarkerValues
-	allPackageMarkers map[string]map[ast.Node]markers.MarkerValues
+	pkg            *packages.Package
+	packageMarkers map[ast.Node]markers.MarkerValues
 
 	file *File
 
@@ -25,9 +33,6 @@ type packageVisitor struct {
 }
 
 func (visitor *packageVisitor) VisitPackage() {
-	visitor.packageMarkers = visitor.allPackageMarkers[visitor.pkg.ID]
-	visitor.collector.markAsSeen(visitor.pkg.ID)
-
 	for _, file := range visitor.pkg.Syntax {
 		ast.Walk(visitor, file)
 	}
@@ -64,60 +69,191 @@ func (visitor *packageVisitor) Visit(node ast.Node) ast.Visitor {
 	}
 }
 
-func visitPackage(pkg *packages.Package, collector *packageCollector, allPackageMarkers map[string]map[ast.Node]markers.MarkerValues) {
+// visitPackage visits the package with its markers. The package must be marked as seen by the caller.
+func visitPackage(pkg *packages.Package, collector *packageCollector, packageMarkers map[ast.Node]markers.MarkerValues) {
 	pkgVisitor := &packageVisitor{
-		collector:         collector,
-		pkg:               pkg,
-		allPackageMarkers: allPackageMarkers,
-	}
-
-	if _, ok := collector.packages[pkg.ID]; !ok {
-		collector.packages[pkg.ID] = pkg
+		collector:      collector,
+		pkg:            pkg,
+		packageMarkers: packageMarkers,
 	}
 
+	collector.addPackage(pkg)
 	pkgVisitor.VisitPackage()
 }
 
+// Options configures how the packages are traversed.
+type Options struct {
+	// Concurrency is the maximum number of the packages collected and visited at the same time.
+	// It defaults to runtime.GOMAXPROCS(0) if it is zero or negative.
+	Concurrency int
+}
+
 func EachFile(collector *markers.Collector, pkgs []*packages.Package, callback FileCallback) error {
-	if collector == nil {
-		return errors.New("collector cannot be nil")
-	}
+	return EachFileWithOptions(collector, pkgs, Options{}, callback)
+}
 
-	if pkgs == nil {
-		return errors.New("packages cannot be nil")
+// EachFileWithOptions collects the markers of the packages and visits them by using a bounded number
+// of workers. A package referencing the types of another package waits until the other one is visited,
+// or visits it itself if no worker has picked it up yet. The callback is invoked for the files sorted by
+// their paths regardless of the concurrency. The markers which cannot be collected do not stop the
+// traversal, their errors are passed to the callback instead.
+func EachFileWithOptions(collector *markers.Collector, pkgs []*packages.Package, options Options, callback FileCallback) error {
+	pkgCollector, fileErrors, err := visitPackages(collector, pkgs, options)
+
+	if err != nil {
+		return err
 	}
 
 	var errs []error
-	packageMarkers := make(map[string]map[ast.Node]markers.MarkerValues)
 
-	for _, pkg := range pkgs {
-		markerValues, err := collector.Collect(pkg)
+	for _, file := range pkgCollector.sortedFiles() {
+		err = callback(file, markers.NewErrorList(fileErrors[file.Path()]))
 
-		if err != nil {
-			errs = append(errs, err.(markers.ErrorList)...)
-			continue
+		if err == SkipAll {
+			break
 		}
 
-		packageMarkers[pkg.ID] = markerValues
+		errs = appendFileErrors(errs, file, err)
+	}
+
+	return markers.NewErrorList(errs)
+}
+
+// visitPackages collects the markers of the packages and visits them. It returns the errors
+// of the markers which cannot be collected by the paths of the files.
+func visitPackages(collector *markers.Collector, pkgs []*packages.Package, options Options) (*packageCollector, map[string][]error, error) {
+	if collector == nil {
+		return nil, nil, errors.New("collector cannot be nil")
+	}
+
+	if pkgs == nil {
+		return nil, nil, errors.New("packages cannot be nil")
 	}
 
-	if len(errs) != 0 {
-		return markers.NewErrorList(errs)
+	results := make([]collectResult, len(pkgs))
+	pkgIndexes := make(map[string]int, len(pkgs))
+
+	for index := len(pkgs) - 1; index >= 0; index-- {
+		pkgIndexes[pkgs[index].ID] = index
 	}
 
 	pkgCollector := newPackageCollector()
+	pkgCollector.visitTraversed = func(pkgId string) bool {
+		index, ok := pkgIndexes[pkgId]
 
-	for _, pkg := range pkgs {
-		if !pkgCollector.isVisited(pkg.ID) || !pkgCollector.isProcessed(pkg.ID) {
-			visitPackage(pkg, pkgCollector, packageMarkers)
+		if !ok {
+			return false
 		}
+
+		results[index] = collectPackage(collector, pkgs[index])
+		visitPackage(pkgs[index], pkgCollector, results[index].markerValues)
+		return true
+	}
+
+	eachPackage(pkgs, options.Concurrency, func(pkg *packages.Package) {
+		pkgCollector.visit(pkg.ID)
+	})
+
+	fileErrors := make(map[string][]error)
+
+	for index, result := range results {
+		if collector.WarningCallback != nil {
+			for _, warning := range result.warnings {
+				collector.WarningCallback(warning)
+			}
+		}
+
+		if result.err != nil {
+			addFileErrors(fileErrors, pkgs[index], result.err)
+		}
+	}
+
+	return pkgCollector, fileErrors, nil
+}
+
+// appendFileErrors appends the errors returned by the callback for the given file with the path of the file.
+func appendFileErrors(errs []error, file *File, err error) []error {
+	for _, err = range markers.FlattenErrors(err) {
+		errs = append(errs, fmt.Errorf("%s: %w", file.Path(), err))
 	}
 
-	for _, pkg := range pkgCollector.files {
-		for _, file := range pkg.elements {
-			callback(file, nil)
+	return errs
+}
+
+// collectResult is the result of collecting the markers of a package.
+type collectResult struct {
+	markerValues map[ast.Node]markers.MarkerValues
+	warnings     []markers.Warning
+	err          error
+}
+
+// eachPackage invokes the function for each package by using the given number of workers, and
+// waits until all the packages are done.
+func eachPackage(pkgs []*packages.Package, concurrency int, fn func(pkg *packages.Package)) {
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	indexes := make(chan int)
+
+	go func() {
+		for index := range pkgs {
+			indexes <- index
 		}
+
+		close(indexes)
+	}()
+
+	var wg sync.WaitGroup
+
+	for worker := 0; worker < concurrency && worker < len(pkgs); worker++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for index := range indexes {
+				fn(pkgs[index])
+			}
+		}()
 	}
 
-	return nil
+	wg.Wait()
+}
+
+// collectPackage collects the markers of the package. The warnings are kept in the result
+// instead of being reported, since the warning callback may not be safe for concurrent use.
+func collectPackage(collector *markers.Collector, pkg *packages.Package) collectResult {
+	result := collectResult{}
+	packageCollector := *collector
+	packageCollector.WarningCallback = func(warning markers.Warning) {
+		result.warnings = append(result.warnings, warning)
+	}
+
+	result.markerValues, result.err = packageCollector.CollectPartial(pkg)
+	return result
+}
+
+// addFileErrors adds the errors of the package to the files they belong to. The errors which
+// do not belong to a file are added to all the files of the package.
+func addFileErrors(fileErrors map[string][]error, pkg *packages.Package, err error) {
+	errs := []error{err}
+
+	if errorList, ok := err.(markers.ErrorList); ok {
+		errs = errorList
+	}
+
+	for _, err = range errs {
+		var parserError markers.ParserError
+
+		if errors.As(err, &parserError) && parserError.FileName != "" {
+			fileErrors[parserError.FileName] = append(fileErrors[parserError.FileName], err)
+			continue
+		}
+
+		for _, file := range pkg.Syntax {
+			fileName := pkg.Fset.Position(file.Pos()).Filename
+			fileErrors[fileName] = append(fileErrors[fileName], err)
+		}
+	}
 }
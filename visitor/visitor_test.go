@@ -1,10 +1,13 @@
 package visitor
 
 import (
+	"errors"
 	"fmt"
 	"github.com/procyon-projects/marker"
 	"github.com/procyon-projects/marker/packages"
 	"github.com/stretchr/testify/assert"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -207,3 +210,137 @@ func assertMarkers(t *testing.T, expectedMarkers markers.MarkerValues, actualMar
 		}
 	}
 }
+
+func eachFileSummary(t *testing.T, pkgs []*packages.Package, concurrency int) []string {
+	registry := markers.NewRegistry()
+	assert.NoError(t, registry.Register("marker:struct-type-level", "github.com/procyon-projects/marker", markers.StructTypeLevel, &StructTypeLevel{}))
+	assert.NoError(t, registry.Register("marker:struct-field-level", "github.com/procyon-projects/marker", markers.FieldLevel, &StructFieldLevel{}))
+	assert.NoError(t, registry.Register("marker:function-level", "github.com/procyon-projects/marker", markers.FunctionLevel, &FunctionLevel{}))
+
+	var summary []string
+	err := EachFileWithOptions(markers.NewCollector(registry), pkgs, Options{Concurrency: concurrency}, func(file *File, err error) error {
+		summary = append(summary, fmt.Sprintf("file %s %v", file.Path(), file.Markers()))
+
+		for index := 0; index < file.Structs().Len(); index++ {
+			structType := file.Structs().At(index)
+			summary = append(summary, fmt.Sprintf("struct %s %v", structType.Name(), structType.Markers()))
+
+			for fieldIndex := 0; fieldIndex < structType.Fields().Len(); fieldIndex++ {
+				field := structType.Fields().At(fieldIndex)
+				summary = append(summary, fmt.Sprintf("field %s %s %v", field.Name(), field.Type(), field.Markers()))
+			}
+		}
+
+		for index := 0; index < file.Functions().Len(); index++ {
+			function := file.Functions().At(index)
+			summary = append(summary, fmt.Sprintf("function %s %v", function, function.Markers()))
+		}
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	return summary
+}
+
+func TestEachFileWithOptions_ShouldVisitPackagesConcurrently(t *testing.T) {
+	result, _ := packages.LoadPackages("../test/...")
+	pkgs := result.Packages()
+
+	sequential := eachFileSummary(t, pkgs, 1)
+	assert.NotEmpty(t, sequential)
+
+	var filePaths []string
+	for _, line := range sequential {
+		if strings.HasPrefix(line, "file ") {
+			filePaths = append(filePaths, line)
+		}
+	}
+
+	assert.True(t, sort.StringsAreSorted(filePaths))
+
+	for _, concurrency := range []int{0, 2, 8} {
+		assert.Equal(t, sequential, eachFileSummary(t, pkgs, concurrency))
+	}
+}
+
+type validatedStructTypeLevel struct {
+	Name string `marker:"Name"`
+	Any  any    `marker:"Any"`
+}
+
+func (m validatedStructTypeLevel) Validate() error {
+	if m.Name == "FriedCookie" {
+		return errors.New("name cannot be 'FriedCookie'")
+	}
+
+	return nil
+}
+
+func TestEachFile_ShouldPassFileErrorsToCallback(t *testing.T) {
+	result, _ := packages.LoadPackages("../test/menu")
+	pkg, _ := result.Lookup("github.com/procyon-projects/marker/test/menu")
+
+	registry := markers.NewRegistry()
+	assert.NoError(t, registry.Register("marker:struct-type-level", "github.com/procyon-projects/marker", markers.StructTypeLevel, &validatedStructTypeLevel{}))
+
+	fileErrors := make(map[string]error)
+	err := EachFile(markers.NewCollector(registry), []*packages.Package{pkg}, func(file *File, err error) error {
+		fileErrors[file.Name()] = err
+
+		if file.Name() == "dessert.go" {
+			friedCookie, _ := file.Structs().FindByName("FriedCookie")
+			assert.Equal(t, 0, friedCookie.Markers().Count())
+
+			cookie, _ := file.Structs().FindByName("cookie")
+			assert.Equal(t, validatedStructTypeLevel{Name: "cookie", Any: map[string]any{"key": "value"}}, cookie.Markers().First("marker:struct-type-level"))
+		}
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, fileErrors, 3)
+	assert.NoError(t, fileErrors["coffee.go"])
+	assert.NoError(t, fileErrors["fresh.go"])
+	assert.Error(t, fileErrors["dessert.go"])
+	assert.Contains(t, fileErrors["dessert.go"].Error(), "name cannot be 'FriedCookie'")
+}
+
+func TestEachFile_ShouldCollectCallbackErrors(t *testing.T) {
+	result, _ := packages.LoadPackages("../test/menu")
+	pkg, _ := result.Lookup("github.com/procyon-projects/marker/test/menu")
+
+	err := EachFile(markers.NewCollector(markers.NewRegistry()), []*packages.Package{pkg}, func(file *File, err error) error {
+		if file.Name() == "fresh.go" {
+			return nil
+		}
+
+		return fmt.Errorf("%s could not be processed", file.Name())
+	})
+
+	assert.Error(t, err)
+	errs := err.(markers.ErrorList)
+	assert.Len(t, errs, 2)
+	assert.True(t, strings.HasSuffix(errs[0].Error(), "coffee.go: coffee.go could not be processed"))
+	assert.True(t, strings.HasSuffix(errs[1].Error(), "dessert.go: dessert.go could not be processed"))
+}
+
+func TestEachFile_ShouldStopWhenSkipAllIsReturned(t *testing.T) {
+	result, _ := packages.LoadPackages("../test/menu")
+	pkg, _ := result.Lookup("github.com/procyon-projects/marker/test/menu")
+
+	var fileNames []string
+	err := EachFile(markers.NewCollector(markers.NewRegistry()), []*packages.Package{pkg}, func(file *File, err error) error {
+		fileNames = append(fileNames, file.Name())
+
+		if file.Name() == "dessert.go" {
+			return SkipAll
+		}
+
+		return errors.New("anyError")
+	})
+
+	assert.Equal(t, []string{"coffee.go", "dessert.go"}, fileNames)
+	assert.Len(t, err.(markers.ErrorList), 1)
+}
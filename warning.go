@@ -0,0 +1,74 @@
+package markers
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+)
+
+// Warning describes a problem which does not prevent a marker from being processed.
+type Warning struct {
+	FileName string
+	Position Position
+	Message  string
+}
+
+func (warning Warning) String() string {
+	return fmt.Sprintf("%s (%d:%d) : %s", warning.FileName, warning.Position.Line, warning.Position.Column, warning.Message)
+}
+
+// WarningCallback is invoked for every warning produced while collecting the markers.
+type WarningCallback func(warning Warning)
+
+func newWarning(message string, position token.Position) Warning {
+	return Warning{
+		FileName: position.Filename,
+		Position: Position{
+			Line:   position.Line,
+			Column: position.Column,
+		},
+		Message: message,
+	}
+}
+
+// deprecationWarnings returns the warnings for the given definition
+// and its arguments which are deprecated but used.
+func deprecationWarnings(definition *Definition, usedArguments map[string]struct{}, position token.Position) []Warning {
+	var warnings []Warning
+
+	if definition.Deprecated {
+		message := fmt.Sprintf("the marker '%s' is deprecated", definition.Name)
+
+		if definition.DeprecationMessage != "" {
+			message = fmt.Sprintf("%s: %s", message, definition.DeprecationMessage)
+		}
+
+		warnings = append(warnings, newWarning(message, position))
+	}
+
+	argumentNames := make([]string, 0, len(usedArguments))
+
+	for argumentName := range usedArguments {
+		argumentNames = append(argumentNames, argumentName)
+	}
+
+	sort.Strings(argumentNames)
+
+	for _, argumentName := range argumentNames {
+		argument, exists := definition.Output.Fields[argumentName]
+
+		if !exists || !argument.Deprecated {
+			continue
+		}
+
+		message := fmt.Sprintf("the argument '%s' of the marker '%s' is deprecated", argumentName, definition.Name)
+
+		if argument.DeprecationMessage != "" {
+			message = fmt.Sprintf("%s: %s", message, argument.DeprecationMessage)
+		}
+
+		warnings = append(warnings, newWarning(message, position))
+	}
+
+	return warnings
+}
@@ -0,0 +1,18 @@
+package markers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWarning_String(t *testing.T) {
+	warning := Warning{
+		FileName: "anyFileName",
+		Position: Position{
+			Line:   2,
+			Column: 4,
+		},
+		Message: "anyWarningMessage",
+	}
+	assert.Equal(t, "anyFileName (2:4) : anyWarningMessage", warning.String())
+}